@@ -0,0 +1,86 @@
+package jo
+
+import (
+	"reflect"
+	"sync"
+)
+
+// masks maps the entry points of state functions to the bytes they accept
+// without raising an error. It is populated on first use, as it depends on
+// the character table being initialized.
+var (
+	masks     map[uintptr]*[256]bool
+	masksOnce sync.Once
+)
+
+// States which hand bytes they don't accept themselves to the state on top
+// of the stack, such as those at the end of a number.
+var popping = make(map[uintptr]bool)
+
+func init() {
+	for _, fn := range []func(*Scanner, byte) Event{
+		afterZero, afterDigit, afterDotDigit, afterEDigit, delayed,
+	} {
+		popping[reflect.ValueOf(fn).Pointer()] = true
+	}
+}
+
+func initMasks() {
+	masks = make(map[uintptr]*[256]bool)
+
+	for _, fn := range []func(*Scanner, byte) Event{
+		beforeValue, beforeFirstObjectKey, afterObjectKey, afterObjectValue,
+		afterObjectComma, beforeFirstArrayElement, afterArrayElement,
+		afterQuote, afterEsc, afterEscU, afterEscU1, afterEscU12, afterEscU123,
+		afterMinus, afterZero, afterDigit, afterDot, afterDotDigit, afterE,
+		afterESign, afterEDigit, afterT, afterTr, afterTru, afterF, afterFa,
+		afterFal, afterFals, afterN, afterNu, afterNul, delayed, afterTopValue,
+		betweenLines, afterError, recovering,
+	} {
+		var mask [256]bool
+
+		// Feed each byte to the state function, using a scratch Scanner.
+		// Popping states are given whitespace-accepting afterTopValue as
+		// the next state, which is why Allowed combines their masks with
+		// that of the actual next state.
+		for c := range mask {
+			s := &Scanner{state: fn, stack: []func(*Scanner, byte) Event{afterTopValue}}
+			mask[c] = fn(s, byte(c)) != Error
+		}
+
+		masks[reflect.ValueOf(fn).Pointer()] = &mask
+	}
+}
+
+// Allowed returns the set of bytes which the Scanner would accept as the
+// next byte of input, without changing its state. It is meant for guiding
+// the generation of JSON text one byte at a time.
+//
+// Only the JSON grammar is taken into account; a byte in the set may still
+// be rejected on account of the duplicate key policy, strict mode or
+// limits. Use Clone to try bytes out where that matters.
+func (s *Scanner) Allowed() [256]bool {
+	masksOnce.Do(initMasks)
+
+	fn := reflect.ValueOf(s.state).Pointer()
+	set := *masks[fn]
+
+	if n := len(s.stack); popping[fn] && n > 0 {
+		next := masks[reflect.ValueOf(s.stack[n-1]).Pointer()]
+		for c, ok := range next {
+			set[c] = set[c] || ok
+		}
+	}
+
+	return set
+}
+
+// CanEnd reports whether the input scanned so far forms a complete
+// document, meaning that a call to End would not return Error. The Scanner's
+// state is left unchanged.
+func (s *Scanner) CanEnd() bool {
+	if s.err != nil && !s.recover {
+		return false
+	}
+	return s.Clone().End() != Error
+}
@@ -0,0 +1,119 @@
+package jo
+
+import (
+	"testing"
+)
+
+// Documents whose every prefix is checked against speculative scanning.
+var allowedDocs = []struct {
+	in    string
+	lines bool
+	setup func(s *Scanner)
+}{
+	{`{"a": [1, -0.5e+3, true, false, null, "x\"é"], "b": {}, "c": []}`, false, nil},
+	{` [ 0 , 12.0E5 , {"" : "" } ] `, false, nil},
+	{`"\\\/\b\f\n\r\t"`, false, nil},
+	{"1\n[2]\n\n{\"a\":3}\n", true, nil},
+	{`[1, {"a": 2}]`, false, func(s *Scanner) { s.SetRecovery(true) }},
+	{`{"a": 1, "a": 2}`, false, func(s *Scanner) { s.SetDuplicatePolicy(RejectDuplicates) }},
+}
+
+func TestAllowed(t *testing.T) {
+	for _, doc := range allowedDocs {
+		s := NewScanner()
+		if doc.lines {
+			s.SetLines(true)
+		}
+		if doc.setup != nil {
+			doc.setup(s)
+		}
+
+		for i := 0; i <= len(doc.in); i++ {
+			set := s.Allowed()
+
+			// Compare the set against the results of actually scanning
+			// each byte. Duplicate keys aren't taken into account, hence
+			// the plain scanner.
+			for c := range set {
+				probe := s.Clone()
+				probe.keys = nil
+
+				if ok := probe.Scan(byte(c)) != Error; ok != set[c] {
+					t.Errorf("%#q after %#q: Allowed()[%q] = %v, want %v", doc.in, doc.in[:i], byte(c), set[c], ok)
+				}
+			}
+
+			if i < len(doc.in) {
+				if ev := s.Scan(doc.in[i]); ev == Error && i != len(doc.in)-1 && doc.setup == nil {
+					t.Fatalf("%#q: unexpected error: %v", doc.in, s.LastError())
+				}
+			}
+		}
+	}
+}
+
+var canEndTests = []struct {
+	in   string
+	want bool
+}{
+	{``, false},
+	{`  `, false},
+	{`1`, true},
+	{`-`, false},
+	{`1.`, false},
+	{`[1]`, true},
+	{`[1`, false},
+	{`"a`, false},
+	{`"a"`, true},
+	{`tru`, false},
+	{`true `, true},
+	{`{"a": 1}}`, false},
+}
+
+func TestCanEnd(t *testing.T) {
+	for _, test := range canEndTests {
+		s := NewScanner()
+		for i := 0; i < len(test.in); i++ {
+			s.Scan(test.in[i])
+		}
+
+		off := s.Offset()
+
+		if got := s.CanEnd(); got != test.want {
+			t.Errorf("%#q: CanEnd() = %v, want %v", test.in, got, test.want)
+		}
+		if s.Offset() != off || s.ending {
+			t.Errorf("%#q: CanEnd changed the Scanner's state", test.in)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	s := NewScanner()
+	s.SetDuplicatePolicy(RejectDuplicates)
+	s.SetLimits(Limits{Members: 2})
+
+	for _, c := range []byte(`[{"a": 1, "b": 2`) {
+		s.Scan(c)
+	}
+
+	// A clone must not be affected by what is fed to the original, and
+	// vice versa.
+	c := s.Clone()
+
+	for _, b := range []byte(`, "a": 3}]`) {
+		c.Scan(b)
+	}
+	if c.LastError() == nil {
+		t.Errorf("clone: expected error")
+	}
+
+	for _, b := range []byte(`}, {"a": 1, "b": 2}]`) {
+		if s.Scan(b) == Error {
+			t.Fatalf("original: unexpected error: %v", s.LastError())
+		}
+	}
+	if s.End() == Error {
+		t.Fatalf("original: unexpected error: %v", s.LastError())
+	}
+}
@@ -0,0 +1,184 @@
+package jo
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonicalize reads a single document from src and writes it to dst in the
+// form defined by the JSON Canonicalization Scheme (RFC 8785): without
+// insignificant whitespace, with object members sorted by key, and with
+// numbers and strings serialized as ECMAScript's JSON.stringify would.
+//
+// As RFC 8785 requires, the document must conform to the I-JSON profile
+// (see SetStrict): documents with duplicate object keys, invalid UTF-8, lone
+// surrogates or numbers outside the range of IEEE 754 doubles are rejected.
+func Canonicalize(dst io.Writer, src io.Reader) error {
+	t := newTokenizer(src)
+	t.s.SetStrict(true)
+
+	v, err := decode(t)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the document doesn't continue past the value.
+	if _, err := t.next(); err != io.EOF {
+		return err
+	}
+
+	buf, err := appendCanonical(nil, v, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = dst.Write(buf)
+	return err
+}
+
+func appendCanonical(dst []byte, v interface{}, ptr string) ([]byte, error) {
+	var err error
+
+	switch v := v.(type) {
+	case object:
+		members := make([]member, len(v))
+		copy(members, v)
+		sort.Stable(byUTF16(members))
+
+		dst = append(dst, '{')
+		for i, m := range members {
+			if !utf8.ValidString(m.key) {
+				return nil, fmt.Errorf("jo: invalid UTF-8 in key at %q", ptr)
+			}
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			dst = appendQuote(dst, []byte(m.key))
+			dst = append(dst, ':')
+
+			sub := ptr + "/" + string(appendPointerToken(nil, []byte(m.key)))
+			if dst, err = appendCanonical(dst, m.val, sub); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, '}')
+
+	case []interface{}:
+		dst = append(dst, '[')
+		for i, el := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonical(dst, el, ptr+"/"+strconv.Itoa(i)); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+
+	case string:
+		if !utf8.ValidString(v) {
+			return nil, fmt.Errorf("jo: invalid UTF-8 in string at %q", ptr)
+		}
+		dst = appendQuote(dst, []byte(v))
+
+	case number:
+		f := v.float()
+		if math.IsInf(f, 0) {
+			return nil, fmt.Errorf("jo: number %s at %q out of range", v, ptr)
+		}
+		dst = appendECMANumber(dst, f)
+
+	case bool:
+		dst = strconv.AppendBool(dst, v)
+
+	case nil:
+		dst = append(dst, "null"...)
+	}
+
+	return dst, nil
+}
+
+// byUTF16 sorts members by their keys' UTF-16 code units.
+type byUTF16 []member
+
+func (m byUTF16) Len() int      { return len(m) }
+func (m byUTF16) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+
+func (m byUTF16) Less(i, j int) bool {
+	a := utf16.Encode([]rune(m[i].key))
+	b := utf16.Encode([]rune(m[j].key))
+
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// appendECMANumber appends f to dst as ECMAScript's Number.prototype.toString
+// would format it.
+func appendECMANumber(dst []byte, f float64) []byte {
+	if f == 0 {
+		return append(dst, '0')
+	}
+	if f < 0 {
+		dst = append(dst, '-')
+		f = -f
+	}
+
+	// Find the shortest decimal digit string that round-trips, and the
+	// position n of the decimal point relative to its first digit.
+	var buf [32]byte
+	e := strconv.AppendFloat(buf[:0], f, 'e', -1, 64)
+
+	var digits []byte
+	var i int
+
+	for ; e[i] != 'e'; i++ {
+		if e[i] != '.' {
+			digits = append(digits, e[i])
+		}
+	}
+
+	exp, _ := strconv.Atoi(string(e[i+1:]))
+	n, k := exp+1, len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		dst = append(dst, digits...)
+		for ; k < n; k++ {
+			dst = append(dst, '0')
+		}
+	case 0 < n && n <= 21:
+		dst = append(dst, digits[:n]...)
+		dst = append(dst, '.')
+		dst = append(dst, digits[n:]...)
+	case -6 < n && n <= 0:
+		dst = append(dst, '0', '.')
+		for ; n < 0; n++ {
+			dst = append(dst, '0')
+		}
+		dst = append(dst, digits...)
+	default:
+		dst = append(dst, digits[0])
+		if k > 1 {
+			dst = append(dst, '.')
+			dst = append(dst, digits[1:]...)
+		}
+		dst = append(dst, 'e')
+		if n-1 >= 0 {
+			dst = append(dst, '+')
+		}
+		dst = strconv.AppendInt(dst, int64(n-1), 10)
+	}
+
+	return dst
+}
@@ -0,0 +1,130 @@
+package jo
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+var canonicalizeTests = []struct {
+	in  string
+	out string
+	err string
+}{
+	{
+		// Example from RFC 8785, section 3.2.2.
+		`{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`,
+		`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		``,
+	},
+	{
+		// Example from RFC 8785, section 3.2.3.
+		`{
+			"\u20ac": "Euro Sign",
+			"\r": "Carriage Return",
+			"\ufb33": "Hebrew Letter Dalet With Dagesh",
+			"1": "One",
+			"\ud83d\ude00": "Emoji: Grinning Face",
+			"\u0080": "Control",
+			"\u00f6": "Latin Small Letter O With Diaeresis"
+		}`,
+		"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+			"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		``,
+	},
+	{
+		`[-0, 0.0, 100, 1e21, 1e20, 123e-9, 1.5e-7, -12.5e-1]`,
+		`[0,0,100,1e+21,100000000000000000000,1.23e-7,1.5e-7,-1.25]`,
+		``,
+	},
+	{
+		`{"a": 1, "b": {"c": 2, "\u0063": 3}}`,
+		``,
+		`duplicate key "c" at "/b/c" (offset 23)`,
+	},
+	{
+		`[1e400]`,
+		``,
+		`"/0" (offset 1): number 1e400 out of range`,
+	},
+	{
+		`["\ud800"]`,
+		``,
+		`"/0" (offset 2): lone surrogate \ud800 in string`,
+	},
+	{
+		`{"a\udc00": 1}`,
+		``,
+		`"/a�" (offset 3): lone surrogate \udc00 in string`,
+	},
+	{
+		`{"a" 1}`,
+		``,
+		`invalid character '1' after object key, expected ':'`,
+	},
+	{
+		`{"a":1} x`,
+		``,
+		`invalid character 'x' after top-level value, expected end of input`,
+	},
+	{
+		`{"a":1}{"b":2}`,
+		``,
+		`invalid character '{' after top-level value, expected end of input`,
+	},
+	{
+		"[1]\n",
+		`[1]`,
+		``,
+	},
+}
+
+func TestCanonicalize(t *testing.T) {
+	for _, test := range canonicalizeTests {
+		var buf bytes.Buffer
+		var msg string
+
+		if err := Canonicalize(&buf, strings.NewReader(test.in)); err != nil {
+			msg = err.Error()
+		}
+
+		if buf.String() != test.out || msg != test.err {
+			t.Errorf("Canonicalize(%#q):", test.in)
+			t.Errorf("  got  %#q (%q)", buf.String(), msg)
+			t.Errorf("  want %#q (%q)", test.out, test.err)
+		}
+	}
+}
+
+var ecmaNumberTests = []struct {
+	in  float64
+	out string
+}{
+	{0, "0"},
+	{math.Copysign(0, -1), "0"},
+	{1, "1"},
+	{-1.5, "-1.5"},
+	{0.1, "0.1"},
+	{1e-6, "0.000001"},
+	{1e-7, "1e-7"},
+	{123456789012345680000, "123456789012345680000"},
+	{1e21, "1e+21"},
+	{9007199254740992, "9007199254740992"},
+	{math.MaxFloat64, "1.7976931348623157e+308"},
+	{5e-324, "5e-324"},
+	{295147905179352830000, "295147905179352830000"},
+	{4.35, "4.35"},
+}
+
+func TestAppendECMANumber(t *testing.T) {
+	for _, test := range ecmaNumberTests {
+		if out := string(appendECMANumber(nil, test.in)); out != test.out {
+			t.Errorf("appendECMANumber(%v) = %s, want %s", test.in, out, test.out)
+		}
+	}
+}
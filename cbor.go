@@ -0,0 +1,488 @@
+package jo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// CBOR major types.
+const (
+	cborUint = iota
+	cborNegint
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// Indefinite-length container markers, and the break code ending them.
+const (
+	cborArrayStart = cborArray<<5 | 31
+	cborMapStart   = cborMap<<5 | 31
+	cborBreak      = 0xff
+)
+
+// ToCBOR converts a single JSON document read from src to CBOR (RFC 8949),
+// and writes it to dst. Objects and arrays are encoded as indefinite-length
+// maps and arrays, so that they can be written as soon as they begin.
+//
+// Integers are encoded as such, falling back to bignums when they don't fit
+// in 64 bits. Other numbers are encoded as the shortest floating point type
+// which represents them exactly.
+func ToCBOR(dst io.Writer, src io.Reader) error {
+	var w = bufio.NewWriter(dst)
+	var t = newTokenizer(src)
+	var buf, str []byte
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Flush()
+			return err
+		}
+
+		buf = buf[:0]
+
+		switch tok.kind {
+		case ObjectStart:
+			buf = append(buf, cborMapStart)
+		case ArrayStart:
+			buf = append(buf, cborArrayStart)
+		case ObjectEnd, ArrayEnd:
+			buf = append(buf, cborBreak)
+		case KeyEnd, StringEnd:
+			str = unquote(str[:0], tok.text)
+			buf = appendCBORHead(buf, cborText, uint64(len(str)))
+			buf = append(buf, str...)
+		case NumberEnd:
+			if buf, err = appendCBORNumber(buf, tok.text); err != nil {
+				w.Flush()
+				return err
+			}
+		case BoolEnd:
+			if tok.text[0] == 't' {
+				buf = append(buf, cborSimple<<5|21)
+			} else {
+				buf = append(buf, cborSimple<<5|20)
+			}
+		case NullEnd:
+			buf = append(buf, cborSimple<<5|22)
+		}
+
+		w.Write(buf)
+	}
+
+	return w.Flush()
+}
+
+// appendCBORHead appends the initial byte of a data item, followed by its
+// argument in the fewest bytes possible.
+func appendCBORHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major<<5|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major<<5|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major<<5|27), n)
+	}
+}
+
+// appendCBORNumber appends a JSON number literal as a CBOR data item.
+func appendCBORNumber(dst, lit []byte) ([]byte, error) {
+	if bytes.IndexAny(lit, ".eE") < 0 {
+		neg := lit[0] == '-'
+		if neg {
+			lit = lit[1:]
+		}
+
+		if n, err := strconv.ParseUint(string(lit), 10, 64); err == nil {
+			if neg && n > 0 {
+				return appendCBORHead(dst, cborNegint, n-1), nil
+			}
+			return appendCBORHead(dst, cborUint, n), nil
+		}
+
+		// Encode integers which don't fit in 64 bits as bignums. Negative
+		// bignums hold -1-n rather than n.
+		var b big.Int
+		b.SetString(string(lit), 10)
+
+		tag := uint64(2)
+		if neg {
+			tag = 3
+			if b.Sub(&b, big.NewInt(1)); b.IsUint64() {
+				return appendCBORHead(dst, cborNegint, b.Uint64()), nil
+			}
+		}
+
+		dst = appendCBORHead(dst, cborTag, tag)
+		dst = appendCBORHead(dst, cborBytes, uint64(len(b.Bytes())))
+		return append(dst, b.Bytes()...), nil
+	}
+
+	f, _ := strconv.ParseFloat(string(lit), 64)
+	if math.IsInf(f, 0) {
+		return nil, fmt.Errorf("jo: number %s out of range", lit)
+	}
+
+	return appendCBORFloat(dst, f), nil
+}
+
+// appendCBORFloat appends f in the shortest floating point encoding which
+// represents it exactly.
+func appendCBORFloat(dst []byte, f float64) []byte {
+	if f32 := float32(f); float64(f32) == f {
+		if h, ok := toFloat16(f32); ok {
+			return binary.BigEndian.AppendUint16(append(dst, cborSimple<<5|25), h)
+		}
+		return binary.BigEndian.AppendUint32(append(dst, cborSimple<<5|26), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, cborSimple<<5|27), math.Float64bits(f))
+}
+
+// toFloat16 converts a finite float32 to IEEE 754 half precision, if it
+// can be done without loss.
+func toFloat16(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mant := bits & 0x7fffff
+
+	switch {
+	case bits&0x7fffffff == 0:
+		return sign, true
+	case -14 <= exp && exp <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mant>>13), true
+	case -24 <= exp && exp < -14:
+		// Subnormal.
+		mant |= 1 << 23
+		shift := uint(13 - 14 - exp)
+		if mant&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(mant>>shift), true
+	default:
+		return 0, false
+	}
+}
+
+// fromFloat16 converts an IEEE 754 half precision number to a float64.
+func fromFloat16(h uint16) float64 {
+	exp := int(h >> 10 & 0x1f)
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// Objects and arrays may be nested no deeper than this in CBOR input.
+const maxCBORDepth = 10000
+
+// FromCBOR converts a single CBOR data item read from src to JSON, and
+// writes it to dst. Any data following the item is rejected.
+//
+// Byte strings are rendered as unpadded base64url strings, and bignums as
+// integers. Map keys must be text strings or integers. Tags other than
+// those of bignums are ignored, as is the distinction between null and
+// undefined. Infinite and NaN floats, and unassigned simple values, can't
+// be represented in JSON and are rejected.
+func FromCBOR(dst io.Writer, src io.Reader) error {
+	d := &cborDecoder{r: bufio.NewReader(src), w: newWriter(dst)}
+
+	err := d.value()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	} else if err == nil {
+		if _, err = d.r.ReadByte(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = fmt.Errorf("jo: unexpected data after CBOR data item")
+		}
+	}
+
+	if err != nil {
+		d.w.flush()
+		return err
+	}
+	return d.w.flush()
+}
+
+// A cborDecoder renders CBOR data items as JSON.
+type cborDecoder struct {
+	r     *bufio.Reader
+	w     *writer
+	depth int
+	buf   []byte
+}
+
+// head reads the initial byte of a data item and its argument. For the
+// simple type info distinguishes floats from simple values.
+func (d *cborDecoder) head() (major, info byte, arg uint64, indefinite bool, err error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return
+	}
+
+	major, info = c>>5, c&0x1f
+
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		var b [8]byte
+		n := 1 << (info - 24)
+		if _, err = io.ReadFull(d.r, b[8-n:]); err != nil {
+			return
+		}
+		arg = binary.BigEndian.Uint64(b[:])
+	case info == 31 && major != cborUint && major != cborNegint && major != cborTag:
+		indefinite = true
+	default:
+		err = fmt.Errorf("jo: invalid CBOR initial byte 0x%02x", c)
+	}
+
+	return
+}
+
+// value renders the next data item.
+func (d *cborDecoder) value() error {
+	major, info, arg, indefinite, err := d.head()
+
+	// Skip tags other than those of bignums. This is done in a loop, so
+	// that long chains of tags can't exhaust the stack.
+	for err == nil && major == cborTag && arg != 2 && arg != 3 {
+		major, info, arg, indefinite, err = d.head()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case cborUint:
+		d.w.raw(strconv.AppendUint(d.buf[:0], arg, 10))
+
+	case cborNegint:
+		if arg == math.MaxUint64 {
+			d.w.raw([]byte("-18446744073709551616"))
+		} else {
+			d.w.raw(strconv.AppendUint(append(d.buf[:0], '-'), arg+1, 10))
+		}
+
+	case cborBytes, cborText:
+		b, err := d.str(major, arg, indefinite)
+		if err != nil {
+			return err
+		}
+		if major == cborBytes {
+			b = base64.RawURLEncoding.AppendEncode(nil, b)
+		} else if !utf8.Valid(b) {
+			return fmt.Errorf("jo: invalid UTF-8 in CBOR text string")
+		}
+		d.w.str(b)
+
+	case cborArray, cborMap:
+		if d.depth++; d.depth > maxCBORDepth {
+			return fmt.Errorf("jo: CBOR nesting too deep")
+		}
+
+		if major == cborArray {
+			d.w.begin('[')
+		} else {
+			d.w.begin('{')
+		}
+
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite {
+				if c, err := d.r.Peek(1); err != nil {
+					return err
+				} else if c[0] == cborBreak {
+					d.r.ReadByte()
+					break
+				}
+			}
+
+			if major == cborMap {
+				if err := d.key(); err != nil {
+					return err
+				}
+			}
+			if err := d.value(); err != nil {
+				return err
+			}
+		}
+
+		if major == cborArray {
+			d.w.end(']')
+		} else {
+			d.w.end('}')
+		}
+		d.depth--
+
+	case cborTag:
+		tag := arg
+
+		major, _, arg, indefinite, err := d.head()
+		if err != nil {
+			return err
+		}
+		if major != cborBytes {
+			return fmt.Errorf("jo: invalid CBOR bignum")
+		}
+
+		b, err := d.str(major, arg, indefinite)
+		if err != nil {
+			return err
+		}
+
+		var n big.Int
+		n.SetBytes(b)
+		if tag == 3 {
+			n.Add(&n, big.NewInt(1))
+			n.Neg(&n)
+		}
+		d.w.raw(n.Append(d.buf[:0], 10))
+
+	case cborSimple:
+		var f float64
+
+		switch info {
+		case 20:
+			d.w.raw([]byte("false"))
+			return nil
+		case 21:
+			d.w.raw([]byte("true"))
+			return nil
+		case 22, 23:
+			d.w.raw([]byte("null"))
+			return nil
+		case 25:
+			f = fromFloat16(uint16(arg))
+		case 26:
+			f = float64(math.Float32frombits(uint32(arg)))
+		case 27:
+			f = math.Float64frombits(arg)
+		case 31:
+			return fmt.Errorf("jo: unexpected CBOR break code")
+		default:
+			return fmt.Errorf("jo: unsupported CBOR simple value %d", arg)
+		}
+
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("jo: CBOR float %v can't be represented in JSON", f)
+		}
+		d.w.raw(appendECMANumber(d.buf[:0], f))
+	}
+
+	return nil
+}
+
+// key renders a map key.
+func (d *cborDecoder) key() error {
+	major, _, arg, indefinite, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case cborText:
+		b, err := d.str(major, arg, indefinite)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(b) {
+			return fmt.Errorf("jo: invalid UTF-8 in CBOR text string")
+		}
+		d.w.key(b)
+	case cborUint:
+		d.w.key(strconv.AppendUint(d.buf[:0], arg, 10))
+	case cborNegint:
+		if arg == math.MaxUint64 {
+			d.w.key([]byte("-18446744073709551616"))
+		} else {
+			d.w.key(strconv.AppendUint(append(d.buf[:0], '-'), arg+1, 10))
+		}
+	default:
+		return fmt.Errorf("jo: unsupported CBOR map key of major type %d", major)
+	}
+
+	return nil
+}
+
+// str reads the contents of a byte or text string, concatenating the
+// chunks of an indefinite-length string.
+func (d *cborDecoder) str(major byte, n uint64, indefinite bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for {
+		if !indefinite {
+			if n > math.MaxInt64 {
+				return nil, fmt.Errorf("jo: CBOR string too long")
+			}
+			// Copy rather than allocate up front, so that a bogus length
+			// can't exhaust memory.
+			if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+
+		c, err := d.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if c[0] == cborBreak {
+			d.r.ReadByte()
+			return buf.Bytes(), nil
+		}
+
+		m, _, arg, chunked, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if m != major || chunked {
+			return nil, fmt.Errorf("jo: invalid chunk in indefinite-length CBOR string")
+		}
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("jo: CBOR string too long")
+		}
+		if _, err := io.CopyN(&buf, d.r, int64(arg)); err != nil {
+			return nil, err
+		}
+	}
+}
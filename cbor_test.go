@@ -0,0 +1,166 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+var toCBORTests = []struct {
+	in  string
+	out string
+}{
+	// Examples from RFC 8949, appendix A, adjusted for indefinite-length
+	// containers where applicable.
+	{`0`, `00`},
+	{`23`, `17`},
+	{`24`, `1818`},
+	{`100`, `1864`},
+	{`1000000`, `1a000f4240`},
+	{`1000000000000`, `1b000000e8d4a51000`},
+	{`18446744073709551615`, `1bffffffffffffffff`},
+	{`18446744073709551616`, `c249010000000000000000`},
+	{`-18446744073709551616`, `3bffffffffffffffff`},
+	{`-18446744073709551617`, `c349010000000000000000`},
+	{`-1`, `20`},
+	{`-1000`, `3903e7`},
+	{`0.0`, `f90000`},
+	{`1.0`, `f93c00`},
+	{`1.5`, `f93e00`},
+	{`65504.0`, `f97bff`},
+	{`100000.0`, `fa47c35000`},
+	{`3.4028234663852886e+38`, `fa7f7fffff`},
+	{`1.1`, `fb3ff199999999999a`},
+	{`1.0e+300`, `fb7e37e43c8800759c`},
+	{`5.960464477539063e-8`, `f90001`},
+	{`0.00006103515625`, `f90400`},
+	{`-4.0`, `f9c400`},
+	{`false`, `f4`},
+	{`true`, `f5`},
+	{`null`, `f6`},
+	{`""`, `60`},
+	{`"a"`, `6161`},
+	{`"IETF"`, `6449455446`},
+	{`"\"\\"`, `62225c`},
+	{`"ü"`, `62c3bc`},
+	{`"𐅑"`, `64f0908591`},
+	{`[]`, `9fff`},
+	{`[1, [2, 3], [4, 5]]`, `9f019f0203ff9f0405ffff`},
+	{`{}`, `bfff`},
+	{`{"a": 1, "b": [2, 3]}`, `bf61610161629f0203ffff`},
+}
+
+func TestToCBOR(t *testing.T) {
+	for _, test := range toCBORTests {
+		var buf bytes.Buffer
+
+		if err := ToCBOR(&buf, strings.NewReader(test.in)); err != nil {
+			t.Errorf("%#q: %s", test.in, err)
+			continue
+		}
+
+		if got := hex.EncodeToString(buf.Bytes()); got != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %s", got)
+			t.Errorf("  want %s", test.out)
+		}
+	}
+}
+
+var fromCBORTests = []struct {
+	in  string
+	out string
+	err string
+}{
+	{`1bffffffffffffffff`, `18446744073709551615`, ``},
+	{`3bffffffffffffffff`, `-18446744073709551616`, ``},
+	{`c249010000000000000000`, `18446744073709551616`, ``},
+	{`c349010000000000000000`, `-18446744073709551617`, ``},
+	{`f90001`, `5.960464477539063e-8`, ``},
+	{`f97bff`, `65504`, ``},
+	{`fa47c35000`, `100000`, ``},
+	{`fb3ff199999999999a`, `1.1`, ``},
+	{`f7`, `null`, ``},
+	{`4401020304`, `"AQIDBA"`, ``},
+	{`5f42010243030405ff`, `"AQIDBAU"`, ``},
+	{`7f657374726561646d696e67ff`, `"streaming"`, ``},
+	{`83010203`, `[1,2,3]`, ``},
+	{`8301820203820405`, `[1,[2,3],[4,5]]`, ``},
+	{`a201020304`, `{"1":2,"3":4}`, ``},
+	{`a26161016162820203`, `{"a":1,"b":[2,3]}`, ``},
+	{`bf61610161629f0203ffff`, `{"a":1,"b":[2,3]}`, ``},
+	{`826161bf61626163ff`, `["a",{"b":"c"}]`, ``},
+	{`c074323031332d30332d32315432303a30343a30305a`, `"2013-03-21T20:04:00Z"`, ``},
+	{`f97c00`, ``, `jo: CBOR float +Inf can't be represented in JSON`},
+	{`f0`, ``, `jo: unsupported CBOR simple value 16`},
+	{`a1f401`, ``, `jo: unsupported CBOR map key of major type 7`},
+	{`62c3`, ``, `unexpected EOF`},
+	{`9f01`, ``, `unexpected EOF`},
+	{`ff`, ``, `jo: unexpected CBOR break code`},
+	{`1c`, ``, `jo: invalid CBOR initial byte 0x1c`},
+	{`c6c6c601`, `1`, ``},
+	{`c6c6`, ``, `unexpected EOF`},
+	{`0102`, ``, `jo: unexpected data after CBOR data item`},
+	{`8101ff`, ``, `jo: unexpected data after CBOR data item`},
+}
+
+func TestFromCBOR(t *testing.T) {
+	for _, test := range fromCBORTests {
+		var buf bytes.Buffer
+		var msg string
+
+		in, _ := hex.DecodeString(test.in)
+		if err := FromCBOR(&buf, bytes.NewReader(in)); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || msg == "" && buf.String() != test.out {
+			t.Errorf("%s:", test.in)
+			t.Errorf("  got  %#q, %q", buf.String(), msg)
+			t.Errorf("  want %#q, %q", test.out, test.err)
+		}
+	}
+}
+
+func TestFromCBORTagChain(t *testing.T) {
+	// Long chains of tags must not exhaust the stack.
+	in := append(bytes.Repeat([]byte{0xc6}, 8<<20), 0x01)
+
+	var buf bytes.Buffer
+	if err := FromCBOR(&buf, bytes.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1" {
+		t.Errorf("got %#q, want %#q", buf.String(), "1")
+	}
+}
+
+var roundTripTests = []string{
+	`{"a": [1, -2, 3.25, 1e-7, "x\ny", true, false, null], "b": {"c": {}}, "d": []}`,
+	`[12345678901234567890123, -0.0, 1.7976931348623157e308, "😀"]`,
+	`"plain"`,
+	`{"": {"": [[[]]]}}`,
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	for _, in := range roundTripTests {
+		var cbor, out bytes.Buffer
+
+		if err := ToCBOR(&cbor, strings.NewReader(in)); err != nil {
+			t.Errorf("ToCBOR(%#q): %s", in, err)
+			continue
+		}
+		if err := FromCBOR(&out, &cbor); err != nil {
+			t.Errorf("FromCBOR(%#q): %s", in, err)
+			continue
+		}
+
+		a, _ := decodeBytes([]byte(in))
+		b, err := decodeBytes(out.Bytes())
+		if err != nil || !equal(a, b) {
+			t.Errorf("%#q:", in)
+			t.Errorf("  got  %#q", out.String())
+		}
+	}
+}
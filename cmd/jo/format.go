@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/erkl/jo"
+)
+
+// indent is the string used for each level of indentation by format.
+const indent = "  "
+
+// format pretty-prints a document, placing each object member and array
+// element on a line of its own.
+func format(dst io.Writer, src *input) error {
+	var w = bufio.NewWriter(dst)
+	var depth int
+	var inString, pending bool
+
+	newline := func() {
+		w.WriteByte('\n')
+		for i := 0; i < depth; i++ {
+			w.WriteString(indent)
+		}
+	}
+
+	err := src.scan(func(c int, ev jo.Event) error {
+		if ev&(jo.StringEnd|jo.KeyEnd) != 0 {
+			inString = false
+		}
+
+		switch {
+		case c == eof:
+			w.WriteByte('\n')
+			return nil
+		case inString:
+			w.WriteByte(byte(c))
+			return nil
+		case ev&jo.Space != 0:
+			return nil
+		}
+
+		if ev&(jo.StringStart|jo.KeyStart) != 0 {
+			inString = true
+		}
+
+		switch c {
+		case '}', ']':
+			depth--
+			if !pending {
+				newline()
+			}
+			pending = false
+			w.WriteByte(byte(c))
+		case ',':
+			w.WriteByte(',')
+			newline()
+		case ':':
+			w.WriteString(": ")
+		default:
+			if pending {
+				newline()
+				pending = false
+			}
+			w.WriteByte(byte(c))
+			if c == '{' || c == '[' {
+				depth++
+				pending = true
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// minify strips all insignificant whitespace from a document.
+func minify(dst io.Writer, src *input) error {
+	var w = bufio.NewWriter(dst)
+
+	err := src.scan(func(c int, ev jo.Event) error {
+		if c == eof {
+			w.WriteByte('\n')
+		} else if ev&jo.Space == 0 {
+			w.WriteByte(byte(c))
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// validate checks a document for syntax errors.
+func validate(dst io.Writer, src *input) error {
+	return src.scan(func(c int, ev jo.Event) error {
+		return nil
+	})
+}
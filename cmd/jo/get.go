@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/erkl/jo"
+)
+
+// A notFoundError is returned by get when a document is valid, but does not
+// contain a value at the requested path.
+type notFoundError struct {
+	name, path string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("%s: no value at path %q", e.name, e.path)
+}
+
+// A frame describes an enclosing object or array.
+type frame struct {
+	array bool
+	index int
+	key   string
+}
+
+// get returns a command which extracts the value at path.
+func get(path []string) command {
+	return func(dst io.Writer, src *input) error {
+		var w = bufio.NewWriter(dst)
+		var stack []frame
+		var key []byte
+		var inKey, found, capturing bool
+		var depth int
+
+		err := src.scan(func(c int, ev jo.Event) error {
+			if ev&jo.KeyEnd != 0 {
+				var s string
+				if err := json.Unmarshal(key, &s); err != nil {
+					return err
+				}
+				stack[len(stack)-1].key = s
+				inKey = false
+			}
+			if ev&(jo.ObjectEnd|jo.ArrayEnd) != 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if capturing && ev&jo.End != 0 && len(stack) == depth {
+				capturing = false
+			}
+
+			if c == eof {
+				if !found {
+					return &notFoundError{src.name, "/" + strings.Join(path, "/")}
+				}
+				w.WriteByte('\n')
+				return nil
+			}
+
+			if ev&jo.KeyStart != 0 {
+				key, inKey = key[:0], true
+			} else if ev&jo.Start != 0 {
+				if n := len(stack); n > 0 && stack[n-1].array {
+					stack[n-1].index++
+				}
+				if !found && matches(stack, path) {
+					found, capturing, depth = true, true, len(stack)
+				}
+				if ev&jo.ObjectStart != 0 {
+					stack = append(stack, frame{})
+				} else if ev&jo.ArrayStart != 0 {
+					stack = append(stack, frame{array: true, index: -1})
+				}
+			}
+
+			if inKey {
+				key = append(key, byte(c))
+			}
+			if capturing {
+				w.WriteByte(byte(c))
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return w.Flush()
+	}
+}
+
+// matches reports whether stack describes the location identified by path.
+func matches(stack []frame, path []string) bool {
+	if len(stack) != len(path) {
+		return false
+	}
+
+	for i, f := range stack {
+		if f.array {
+			if path[i] != strconv.Itoa(f.index) {
+				return false
+			}
+		} else if path[i] != f.key {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/erkl/jo"
+)
+
+// eof is passed in place of a byte when the end of input has been reached.
+const eof = -1
+
+// An input is a named stream of JSON text.
+type input struct {
+	name string
+	r    io.Reader
+}
+
+// A posError is a syntax error annotated with its position in the input.
+type posError struct {
+	name      string
+	line, col int
+	err       error
+
+	// Excerpt of the offending line, with a caret marking the position.
+	excerpt string
+}
+
+func (e *posError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.name, e.line, e.col, e.err)
+}
+
+// Bytes of the current line kept around for error excerpts.
+const maxLine = 256
+
+// scan feeds the input through a Scanner, invoking fn with each byte and the
+// event it produced. Once the input has been exhausted fn is invoked one last
+// time with c set to eof. Syntax errors are returned as *posError values.
+func (in *input) scan(fn func(c int, ev jo.Event) error) error {
+	var buf = make([]byte, 32*1024)
+	var s = jo.NewScanner()
+	var line, col = 1, 0
+	var text []byte
+
+	for {
+		n, err := in.r.Read(buf)
+
+		for i, c := range buf[:n] {
+			col++
+
+			if len(text) == maxLine {
+				text = text[:copy(text, text[maxLine-64:])]
+			}
+			text = append(text, c)
+
+			ev := s.Scan(c)
+			if ev == jo.Error {
+				off := len(text) - 1
+
+				// Include the remainder of the line in the excerpt.
+				for _, c := range buf[i+1 : n] {
+					if c == '\n' || len(text) > off+64 {
+						break
+					}
+					text = append(text, c)
+				}
+
+				return &posError{in.name, line, col, s.LastError(), jo.Excerpt(text, int64(off))}
+			}
+
+			if err := fn(int(c), ev); err != nil {
+				return err
+			}
+
+			if c == '\n' {
+				line, col = line+1, 0
+				text = text[:0]
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	ev := s.End()
+	if ev == jo.Error {
+		return &posError{in.name, line, col + 1, s.LastError(), jo.Excerpt(text, int64(len(text)))}
+	}
+
+	return fn(eof, ev)
+}
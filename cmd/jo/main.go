@@ -0,0 +1,142 @@
+// Command jo is a small command-line tool for inspecting and reformatting
+// JSON documents.
+//
+// Usage:
+//
+//	jo fmt [file ...]         pretty-print documents
+//	jo min [file ...]         strip insignificant whitespace
+//	jo valid [file ...]       check documents for syntax errors
+//	jo get <path> [file ...]  print the value at a JSON Pointer path
+//	jo stats [file ...]       print document statistics
+//
+// Input is read from the named files, or from standard input if none are
+// given (or if a file is named "-"). All input is streamed through a
+// jo.Scanner, so documents are never held in memory in their entirety.
+//
+// Syntax errors are reported as "file:line:col: message". The exit status
+// is 0 on success, 1 if any document is invalid (or, for get, if the path
+// does not exist), and 2 on usage or I/O errors.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/erkl/jo"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitFailure = 2
+)
+
+const usage = `usage: jo <command> [arguments] [file ...]
+
+commands:
+  fmt           pretty-print documents
+  min           strip insignificant whitespace
+  valid         check documents for syntax errors
+  get <path>    print the value at a JSON Pointer path
+  stats         print document statistics
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// A command processes a single named input.
+type command func(dst io.Writer, src *input) error
+
+// run executes the command line in args and returns an exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitFailure
+	}
+
+	var cmd command
+	var name, files = args[0], args[1:]
+
+	switch name {
+	case "fmt":
+		cmd = format
+	case "min":
+		cmd = minify
+	case "valid":
+		cmd = validate
+	case "get":
+		if len(files) == 0 {
+			fmt.Fprintf(stderr, "jo get: missing path argument\n")
+			return exitFailure
+		}
+		path, err := jo.SplitPointer(files[0])
+		if err != nil {
+			fmt.Fprintf(stderr, "jo get: %s\n", err)
+			return exitFailure
+		}
+		cmd, files = get(path), files[1:]
+	case "stats":
+		cmd = stats
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "jo: unknown command %q\n\n%s", name, usage)
+		return exitFailure
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	code := exitOK
+
+	for _, file := range files {
+		err := process(cmd, file, stdin, stdout)
+		if err == nil {
+			continue
+		}
+
+		fmt.Fprintf(stderr, "%s\n", err)
+
+		var perr *posError
+		var nerr *notFoundError
+
+		if errors.As(err, &perr) {
+			fmt.Fprintf(stderr, "%s\n", perr.excerpt)
+		}
+
+		if errors.As(err, &perr) || errors.As(err, &nerr) {
+			if code == exitOK {
+				code = exitInvalid
+			}
+		} else {
+			code = exitFailure
+		}
+	}
+
+	return code
+}
+
+// process opens a single file (or standard input, for "-") and runs cmd on
+// its contents.
+func process(cmd command, file string, stdin io.Reader, stdout io.Writer) error {
+	var r io.Reader
+
+	if file == "-" {
+		r, file = stdin, "<stdin>"
+	} else {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	return cmd(stdout, &input{name: file, r: r})
+}
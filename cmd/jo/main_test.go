@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var runTests = []struct {
+	args   []string
+	stdin  string
+	stdout string
+	stderr string
+	code   int
+}{
+	{
+		[]string{"fmt"},
+		`{"a":[1,2,{}],"b":{"c":"x, y: [z]"},"d":[]}`,
+		"{\n  \"a\": [\n    1,\n    2,\n    {}\n  ],\n  \"b\": {\n    \"c\": \"x, y: [z]\"\n  },\n  \"d\": []\n}\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"min"},
+		" { \"a\" : [ 1 , \" 2 \" ] }\n",
+		"{\"a\":[1,\" 2 \"]}\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"valid", "-"},
+		`[true, false, null]`,
+		"",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"valid"},
+		"{\n  \"a\": 1,\n  \"b\" 2\n}",
+		"",
+		"<stdin>:3:7: invalid character '2' after object key, expected ':'\n" +
+			"  \"b\" 2\n" +
+			"      ^\n",
+		exitInvalid,
+	},
+	{
+		[]string{"valid"},
+		"[1,\n2",
+		"",
+		"<stdin>:2:2: unexpected end of JSON input, expected digit, '.', 'e', 'E', ',' or ']'\n" +
+			"2\n" +
+			" ^\n",
+		exitInvalid,
+	},
+	{
+		[]string{"get", "/a/1/b~1c"},
+		`{"a": [0, {"x": 1, "b/c": {"d" : [ true ]}}]}`,
+		"{\"d\" : [ true ]}\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"get", "/a"},
+		`{"a": 12.5e3}`,
+		"12.5e3\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"get", ""},
+		`"foo"`,
+		"\"foo\"\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"get", "/b"},
+		`{"a": 1}`,
+		"",
+		"<stdin>: no value at path \"/b\"\n",
+		exitInvalid,
+	},
+	{
+		[]string{"get", "a"},
+		`{}`,
+		"",
+		"jo get: JSON Pointer \"a\" doesn't start with '/'\n",
+		exitFailure,
+	},
+	{
+		[]string{"get", "/a~2b"},
+		`{}`,
+		"",
+		"jo get: invalid escape sequence in JSON Pointer \"/a~2b\"\n",
+		exitFailure,
+	},
+	{
+		[]string{"get", "/a~0b/~"},
+		`{}`,
+		"",
+		"jo get: invalid escape sequence in JSON Pointer \"/a~0b/~\"\n",
+		exitFailure,
+	},
+	{
+		[]string{"get", "/a~0b/0"},
+		`{"a~b": [1]}`,
+		"1\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"stats"},
+		`{"a": [1, "x", {"b": null}], "c": false}`,
+		"<stdin>:\n" +
+			"  bytes     40\n" +
+			"  depth     3\n" +
+			"  objects   2\n" +
+			"  keys      3\n" +
+			"  arrays    1\n" +
+			"  strings   1\n" +
+			"  numbers   1\n" +
+			"  booleans  1\n" +
+			"  nulls     1\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"stats"},
+		"[[1]]\n",
+		"<stdin>:\n" +
+			"  bytes     6\n" +
+			"  depth     2\n" +
+			"  objects   0\n" +
+			"  keys      0\n" +
+			"  arrays    2\n" +
+			"  strings   0\n" +
+			"  numbers   1\n" +
+			"  booleans  0\n" +
+			"  nulls     0\n",
+		"",
+		exitOK,
+	},
+	{
+		[]string{"stats"},
+		"[1 2]",
+		"",
+		"<stdin>:1:4: invalid character '2' after array element, expected ',' or ']'\n" +
+			"[1 2]\n" +
+			"   ^\n",
+		exitInvalid,
+	},
+	{
+		[]string{"valid", "does-not-exist.json"},
+		``,
+		"",
+		"open does-not-exist.json: no such file or directory\n",
+		exitFailure,
+	},
+	{
+		[]string{"frobnicate"},
+		``,
+		"",
+		"jo: unknown command \"frobnicate\"\n\n" + usage,
+		exitFailure,
+	},
+}
+
+func TestRun(t *testing.T) {
+	for _, test := range runTests {
+		var stdout, stderr bytes.Buffer
+
+		code := run(test.args, strings.NewReader(test.stdin), &stdout, &stderr)
+
+		if code != test.code || stdout.String() != test.stdout || stderr.String() != test.stderr {
+			t.Errorf("jo %s <<< %#q:", strings.Join(test.args, " "), test.stdin)
+			t.Errorf("  got  %d, %q, %q", code, stdout.String(), stderr.String())
+			t.Errorf("  want %d, %q, %q", test.code, test.stdout, test.stderr)
+		}
+	}
+}
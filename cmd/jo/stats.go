@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/erkl/jo"
+)
+
+// stats prints a summary of the values found in a document.
+func stats(dst io.Writer, src *input) error {
+	var st jo.Stats
+
+	// The document is validated by src.scan, so that syntax errors are
+	// reported with their position, and tallied by jo.Stats as it streams
+	// past.
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		err := st.Add(pr)
+		io.Copy(ioutil.Discard, pr)
+		done <- err
+	}()
+
+	tee := &input{src.name, io.TeeReader(src.r, pw)}
+	err := tee.scan(func(c int, ev jo.Event) error { return nil })
+	pw.Close()
+
+	if aerr := <-done; err == nil {
+		err = aerr
+	}
+	if err != nil {
+		return err
+	}
+
+	var keys int
+	for _, n := range st.Keys {
+		keys += n
+	}
+
+	_, err = fmt.Fprintf(dst, "%s:\n"+
+		"  bytes     %d\n"+
+		"  depth     %d\n"+
+		"  objects   %d\n"+
+		"  keys      %d\n"+
+		"  arrays    %d\n"+
+		"  strings   %d\n"+
+		"  numbers   %d\n"+
+		"  booleans  %d\n"+
+		"  nulls     %d\n",
+		src.name, st.Bytes, st.MaxDepth,
+		st.Kinds.Object, keys, st.Kinds.Array,
+		st.Kinds.String, st.Kinds.Number, st.Kinds.Bool,
+		st.Kinds.Null)
+
+	return err
+}
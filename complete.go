@@ -0,0 +1,126 @@
+package jo
+
+import (
+	"unicode/utf8"
+)
+
+// CloseOpen turns a truncated document, such as a prefix of a response
+// still being streamed, into a complete one. It appends whatever is needed
+// to terminate an unfinished string and close all open objects and arrays,
+// and removes what can't be completed without making up data: a dangling
+// key (along with its colon), a trailing comma, a partial true, false or
+// null, a partial escape sequence or UTF-8 sequence within a string, and a
+// dangling decimal point or exponent in a number.
+//
+// If prefix contains a syntax error, the part preceding it is completed
+// instead. If no value can be salvaged, as when prefix is empty or holds
+// only a partial literal, CloseOpen returns nil.
+//
+// The returned slice never shares memory with prefix.
+func CloseOpen(prefix []byte) []byte {
+	s := NewScanner()
+
+	// Length of the prefix up to the end of the last complete member or
+	// element, or the start of the innermost open object or array.
+	var cut int
+
+	for i, c := range prefix {
+		ev := s.Scan(c)
+		if ev == Error {
+			return CloseOpen(prefix[:i])
+		}
+
+		if end := ev & End; end != 0 && end != KeyEnd {
+			cut = i
+		}
+		if ev&(ObjectStart|ArrayStart) != 0 {
+			cut = i + 1
+		}
+	}
+
+	// Figure out how much of the prefix to keep, based on where it ends.
+	var n = len(prefix)
+	var quote bool
+
+	switch st := s.state; {
+	case same(st, afterQuote), same(st, afterEsc), same(st, afterEscU),
+		same(st, afterEscU1), same(st, afterEscU12), same(st, afterEscU123):
+		if s.end == KeyEnd {
+			n = -1
+			break
+		}
+
+		// Drop a partial escape sequence.
+		switch {
+		case same(st, afterEsc):
+			n -= 1
+		case same(st, afterEscU):
+			n -= 2
+		case same(st, afterEscU1):
+			n -= 3
+		case same(st, afterEscU12):
+			n -= 4
+		case same(st, afterEscU123):
+			n -= 5
+		}
+
+		// Drop a partial UTF-8 sequence.
+		i := n
+		for i > n-utf8.UTFMax && prefix[i-1]&0xC0 == 0x80 {
+			i--
+		}
+		if i > n-utf8.UTFMax && prefix[i-1] >= 0xC0 && !utf8.FullRune(prefix[i-1:n]) {
+			n = i - 1
+		}
+
+		quote = true
+	case same(st, delayed):
+		if s.end == KeyEnd {
+			n = -1
+		}
+	case same(st, afterDot), same(st, afterE):
+		n -= 1
+	case same(st, afterESign):
+		n -= 2
+	case same(st, afterZero), same(st, afterDigit), same(st, afterDotDigit), same(st, afterEDigit),
+		same(st, beforeFirstObjectKey), same(st, afterObjectValue),
+		same(st, beforeFirstArrayElement), same(st, afterArrayElement),
+		same(st, afterTopValue):
+	default:
+		// A dangling key, colon or comma, a partial literal, or nothing at
+		// all.
+		n = -1
+	}
+
+	// Close all open objects and arrays, innermost first.
+	var closers []byte
+
+	for i := len(s.stack); i >= 0; i-- {
+		fn := s.state
+		if i < len(s.stack) {
+			fn = s.stack[i]
+		}
+
+		switch {
+		case same(fn, beforeFirstObjectKey), same(fn, afterObjectKey),
+			same(fn, afterObjectValue), same(fn, afterObjectComma):
+			closers = append(closers, '}')
+		case same(fn, beforeFirstArrayElement), same(fn, afterArrayElement):
+			closers = append(closers, ']')
+		}
+	}
+
+	if n < 0 {
+		if len(closers) == 0 {
+			return nil
+		}
+		n, quote = cut, false
+	}
+
+	doc := make([]byte, 0, n+1+len(closers))
+	doc = append(doc, prefix[:n]...)
+	if quote {
+		doc = append(doc, '"')
+	}
+	return append(doc, closers...)
+}
@@ -0,0 +1,86 @@
+package jo
+
+import (
+	"testing"
+)
+
+var closeOpenTests = []struct {
+	in  string
+	out string
+}{
+	// Complete documents are left alone.
+	{`{"a": [1, true]}`, `{"a": [1, true]}`},
+	{`12 `, `12 `},
+
+	// Strings.
+	{`"ab`, `"ab"`},
+	{`["ab\`, `["ab"]`},
+	{`["ab\u`, `["ab"]`},
+	{`["ab\u00`, `["ab"]`},
+	{`["ab\u00e`, `["ab"]`},
+	{`["abé`, `["abé"]`},
+	{`["ab\n`, `["ab\n"]`},
+	{"\"\xc3", `""`},
+	{"\"a\xe2\x82", `"a"`},
+	{"\"a\xe2\x82\xac", "\"a\xe2\x82\xac\""},
+
+	// Numbers.
+	{`[1`, `[1]`},
+	{`[-`, `[]`},
+	{`[-0`, `[-0]`},
+	{`[1.`, `[1]`},
+	{`[1.5`, `[1.5]`},
+	{`[1.5e`, `[1.5]`},
+	{`[1e-`, `[1]`},
+	{`[1e-2`, `[1e-2]`},
+	{`-`, ``},
+
+	// Literals.
+	{`[true, fa`, `[true]`},
+	{`{"a": nul`, `{}`},
+	{`{"a": null`, `{"a": null}`},
+	{`tr`, ``},
+
+	// Keys, colons and commas.
+	{`{"a": 1, "b`, `{"a": 1}`},
+	{`{"a": 1, "b"`, `{"a": 1}`},
+	{`{"a": 1, "b" `, `{"a": 1}`},
+	{`{"a": 1, "b":`, `{"a": 1}`},
+	{`{"a": 1,`, `{"a": 1}`},
+	{`{"a": 1 ,  `, `{"a": 1}`},
+	{`[[1], {"a"`, `[[1], {}]`},
+	{`[{},`, `[{}]`},
+	{`{"a": {}`, `{"a": {}}`},
+	{`{"a": [{"b": ["c", {"d": "e`, `{"a": [{"b": ["c", {"d": "e"}]}]}`},
+
+	// Openings.
+	{`{`, `{}`},
+	{`[ `, `[ ]`},
+	{`[[[`, `[[[]]]`},
+	{``, ``},
+	{`  `, ``},
+
+	// Syntax errors.
+	{`[1 2`, `[1 ]`},
+	{`{"a": 1}}`, `{"a": 1}`},
+	{`{"a" 1`, `{}`},
+	{`[1, x`, `[1]`},
+}
+
+func TestCloseOpen(t *testing.T) {
+	for _, test := range closeOpenTests {
+		got := CloseOpen([]byte(test.in))
+
+		if string(got) != test.out || test.out == "" && got != nil {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %#q", got)
+			t.Errorf("  want %#q", test.out)
+		}
+
+		if got != nil {
+			if _, err := decodeBytes(got); err != nil {
+				t.Errorf("%#q: result %#q is invalid: %v", test.in, got, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVOptions configures ToCSV.
+type CSVOptions struct {
+	// Field delimiter. The default is ',', and '\t' produces TSV.
+	Comma rune
+
+	// Read a stream of newline-separated records (JSON Lines) rather than
+	// a single array of records.
+	Lines bool
+
+	// Number of records from which to infer the header. If zero, every
+	// record is taken into account, at the cost of reading the entire
+	// input into memory so that it can be scanned twice.
+	Sample int
+}
+
+// ToCSV converts an array of objects (or, with opts.Lines set, a stream of
+// newline-separated objects) read from src to CSV, and writes it to dst.
+// Each object becomes a row. The opts argument may be nil.
+//
+// Nested objects and arrays are flattened, their members and elements
+// becoming columns named by joining keys and indices with dots, as in
+// "address.city" or "tags.0". Empty objects and arrays are written as "{}"
+// and "[]". Strings are written without quotes, null as an empty field.
+//
+// The header lists every column seen in the sampled records, in the order
+// they first appear. Columns which first appear in later records are left
+// out.
+func ToCSV(dst io.Writer, src io.Reader, opts *CSVOptions) error {
+	if opts == nil {
+		opts = new(CSVOptions)
+	}
+
+	if opts.Sample == 0 {
+		doc, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+
+		header, _, err := sampleCSV(newCSVReader(bytes.NewReader(doc), opts.Lines), -1)
+		if err != nil {
+			return err
+		}
+
+		return writeCSV(dst, newCSVReader(bytes.NewReader(doc), opts.Lines), opts, header, nil)
+	}
+
+	r := newCSVReader(src, opts.Lines)
+
+	header, sample, err := sampleCSV(r, opts.Sample)
+	if err != nil {
+		return err
+	}
+
+	return writeCSV(dst, r, opts, header, sample)
+}
+
+// sampleCSV reads up to n records (or all of them, if n is negative) and
+// collects their columns.
+func sampleCSV(r *csvReader, n int) ([]string, [][]cell, error) {
+	var header []string
+	var sample [][]cell
+	var seen = make(map[string]bool)
+
+	for i := 0; i != n; i++ {
+		rec, err := r.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		for _, c := range rec {
+			if !seen[c.name] {
+				seen[c.name] = true
+				header = append(header, c.name)
+			}
+		}
+
+		// Only hang on to the records if they're going to be written.
+		if n > 0 {
+			sample = append(sample, rec)
+		}
+	}
+
+	return header, sample, nil
+}
+
+// writeCSV writes the header and the sampled records, followed by the rest
+// of r's records.
+func writeCSV(dst io.Writer, r *csvReader, opts *CSVOptions, header []string, sample [][]cell) error {
+	w := csv.NewWriter(dst)
+	if opts.Comma != 0 {
+		w.Comma = opts.Comma
+	}
+
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+
+	row := make([]string, len(header))
+
+	write := func(rec []cell) error {
+		for i := range row {
+			row[i] = ""
+		}
+		for _, c := range rec {
+			if i, ok := index[c.name]; ok {
+				row[i] = c.value
+			}
+		}
+		return w.Write(row)
+	}
+
+	for _, rec := range sample {
+		if err := write(rec); err != nil {
+			return err
+		}
+	}
+
+	for {
+		rec, err := r.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Flush()
+			return err
+		}
+
+		if err := write(rec); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// A cell is a flattened value within a record.
+type cell struct {
+	name, value string
+}
+
+// A csvReader reads records from an array or a JSON Lines stream.
+type csvReader struct {
+	t     *tokenizer
+	lines bool
+	n     int
+	str   []byte
+}
+
+func newCSVReader(r io.Reader, lines bool) *csvReader {
+	t := newTokenizer(r)
+	if lines {
+		t.s.SetLines(true)
+	}
+	return &csvReader{t: t, lines: lines}
+}
+
+// next reads the next record, returning io.EOF after the last one.
+func (r *csvReader) next() ([]cell, error) {
+	tok, err := r.t.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.lines && r.n == 0 {
+		if tok.kind != ArrayStart {
+			return nil, fmt.Errorf("jo: CSV input must be an array of objects")
+		}
+		if tok, err = r.t.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !r.lines && tok.kind == ArrayEnd {
+		// Make sure the document doesn't continue past the array.
+		if _, err := r.t.next(); err != io.EOF {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if tok.kind != ObjectStart {
+		return nil, fmt.Errorf("jo: record %d is not an object", r.n)
+	}
+
+	r.n++
+
+	var rec []cell
+
+	for depth := 1; depth > 0; {
+		prev := tok.kind
+		if tok, err = r.t.next(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		switch tok.kind {
+		case ObjectStart, ArrayStart:
+			depth++
+		case ObjectEnd, ArrayEnd:
+			depth--
+
+			// Empty objects and arrays are written as such, as Flatten
+			// would, rather than vanishing without a trace.
+			outer := r.t.stack[:len(r.t.stack)-1]
+			if depth > 0 && prev == ObjectStart && tok.kind == ObjectEnd {
+				rec = append(rec, cell{r.column(outer), "{}"})
+			} else if depth > 0 && prev == ArrayStart && tok.kind == ArrayEnd {
+				rec = append(rec, cell{r.column(outer), "[]"})
+			}
+		case KeyEnd:
+		case StringEnd:
+			r.str = unquote(r.str[:0], tok.text)
+			rec = append(rec, cell{r.column(r.t.stack), string(r.str)})
+		case NullEnd:
+			rec = append(rec, cell{r.column(r.t.stack), ""})
+		default:
+			rec = append(rec, cell{r.column(r.t.stack), string(tok.text)})
+		}
+	}
+
+	return rec, nil
+}
+
+// column names the column of a value nested within stack.
+func (r *csvReader) column(stack frames) string {
+	var buf []byte
+
+	// Skip the top-level array, if any.
+	if !r.lines {
+		stack = stack[1:]
+	}
+
+	for i, f := range stack {
+		if i > 0 {
+			buf = append(buf, '.')
+		}
+		if f.array {
+			buf = strconv.AppendInt(buf, int64(f.index), 10)
+		} else {
+			buf = append(buf, f.key...)
+		}
+	}
+
+	return string(buf)
+}
@@ -0,0 +1,97 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var toCSVTests = []struct {
+	opts *CSVOptions
+	in   string
+	out  string
+	err  string
+}{
+	{
+		nil,
+		`[{"id": 1, "name": "Ann", "address": {"city": "Oslo", "zip": "0150"}},
+		  {"id": 2, "name": "Bob, Jr.", "tags": ["a", "b"], "address": null},
+		  {"name": "Cy \"C\"", "ok": true}]`,
+		"id,name,address.city,address.zip,tags.0,tags.1,address,ok\n" +
+			"1,Ann,Oslo,0150,,,,\n" +
+			"2,\"Bob, Jr.\",,,a,b,,\n" +
+			",\"Cy \"\"C\"\"\",,,,,,true\n",
+		``,
+	},
+	{
+		&CSVOptions{Sample: 1, Comma: '\t'},
+		`[{"a": 1, "b": {"c": 2.5e3}}, {"b": {"c": "x"}, "d": 4, "a": 3}]`,
+		"a\tb.c\n" +
+			"1\t2.5e3\n" +
+			"3\tx\n",
+		``,
+	},
+	{
+		&CSVOptions{Lines: true},
+		"{\"a\": \"x\"}\n{\"b\": \"y\\nz\"}\n",
+		"a,b\n" +
+			"x,\n" +
+			",\"y\nz\"\n",
+		``,
+	},
+	{
+		nil,
+		`[]`,
+		"\n",
+		``,
+	},
+	{
+		nil,
+		`[{"a": {}, "b": [], "c": [[], {"d": {}}]}, {}]`,
+		"a,b,c.0,c.1.d\n" +
+			"{},[],[],{}\n" +
+			",,,\n",
+		``,
+	},
+	{
+		nil,
+		`[{"a": 1}] xyz`,
+		``,
+		`invalid character 'x' after top-level value, expected end of input`,
+	},
+	{
+		nil,
+		`[{"a": 1}] [`,
+		``,
+		`invalid character '[' after top-level value, expected end of input`,
+	},
+	{
+		nil,
+		`{"a": 1}`,
+		``,
+		`jo: CSV input must be an array of objects`,
+	},
+	{
+		&CSVOptions{Sample: 5},
+		`[{"a": 1}, 2]`,
+		``,
+		`jo: record 1 is not an object`,
+	},
+}
+
+func TestToCSV(t *testing.T) {
+	for _, test := range toCSVTests {
+		var buf bytes.Buffer
+		var msg string
+
+		if err := ToCSV(&buf, strings.NewReader(test.in), test.opts); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || msg == "" && buf.String() != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %q, %q", buf.String(), msg)
+			t.Errorf("  want %q, %q", test.out, test.err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package jo
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A DuplicatePolicy determines how a Scanner treats repeated keys within an
+// object. Keys are compared after escape sequences have been resolved.
+type DuplicatePolicy int
+
+const (
+	// Duplicate keys are neither detected nor reported. This is the
+	// default, and carries no overhead.
+	AllowDuplicates DuplicatePolicy = iota
+
+	// Duplicate keys are reported as errors. In recovery mode they are
+	// only recorded (see Scanner.Errors), and scanning carries on as
+	// normal.
+	RejectDuplicates
+
+	// Duplicate keys are detected and flagged (see Scanner.Duplicate). When
+	// decoding, the first or last occurrence of a key takes precedence.
+	FirstWins
+	LastWins
+)
+
+// A DuplicateKeyError is returned when a duplicate key is encountered while
+// duplicates are being rejected.
+type DuplicateKeyError struct {
+	// The unescaped key.
+	Key string
+
+	// Location of the duplicate member, and offset of its key.
+	Pointer string
+	Offset  int64
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %q at %q (offset %d)", e.Key, e.Pointer, e.Offset)
+}
+
+// SetDuplicatePolicy configures how the Scanner treats duplicate object
+// keys. It also resets the Scanner.
+func (s *Scanner) SetDuplicatePolicy(p DuplicatePolicy) {
+	s.track(func(k *keyTracker) { k.policy = p })
+}
+
+// Duplicate reports whether the key which ended with the most recent
+// KeyEnd event repeats an earlier key in the same object. It always
+// returns false unless the duplicate policy is FirstWins or LastWins.
+func (s *Scanner) Duplicate() bool {
+	return s.keys != nil && s.keys.dup
+}
+
+// A keyTracker records the keys of all open objects, along with enough
+// information to check the strings and numbers in strict mode.
+type keyTracker struct {
+	policy DuplicatePolicy
+	strict bool
+
+	frames []keyFrame
+
+	// Raw text and offset of the literal being scanned. Only keys are
+	// recorded unless in strict mode.
+	lit    []byte
+	inLit  bool
+	litOff int64
+
+	// Whether the last key was a duplicate.
+	dup bool
+}
+
+// A keyFrame describes an open object or array.
+type keyFrame struct {
+	array bool
+	index int
+	key   string
+	seen  map[string]bool
+}
+
+func (k *keyTracker) reset() {
+	k.frames = k.frames[:0]
+	k.inLit = false
+	k.dup = false
+}
+
+func (k *keyTracker) clone() *keyTracker {
+	c := *k
+	c.frames = make([]keyFrame, len(k.frames))
+	c.lit = append([]byte{}, k.lit...)
+
+	for i, f := range k.frames {
+		if f.seen != nil {
+			seen := make(map[string]bool, len(f.seen))
+			for key := range f.seen {
+				seen[key] = true
+			}
+			f.seen = seen
+		}
+		c.frames[i] = f
+	}
+
+	return &c
+}
+
+// track reconfigures the Scanner's keyTracker, which is only kept around
+// while it has something to do. It also resets the Scanner.
+func (s *Scanner) track(fn func(k *keyTracker)) {
+	if s.keys == nil {
+		s.keys = new(keyTracker)
+	}
+
+	fn(s.keys)
+
+	if s.keys.policy == AllowDuplicates && !s.keys.strict {
+		s.keys = nil
+	}
+
+	s.Reset()
+}
+
+// observe updates the keyTracker with another event, returning Error if
+// a duplicate key or strict mode violation is to be rejected.
+func (s *Scanner) observe(c byte, ev Event) Event {
+	k := s.keys
+
+	// The frames may be out of step with the input after recovering from
+	// a syntax error, hence the bounds checks.
+	n := len(k.frames)
+
+	var err error
+
+	if end := ev & End; end == ObjectEnd || end == ArrayEnd {
+		if n > 0 {
+			k.frames = k.frames[:n-1]
+		}
+	} else if end == KeyEnd && n > 0 && !k.frames[n-1].array {
+		k.inLit = false
+
+		f := &k.frames[n-1]
+		f.key = string(unquote(nil, k.lit))
+
+		if k.strict {
+			if err := k.checkString(); err != nil {
+				if ev = s.reject(err, ev); ev == Error {
+					return Error
+				}
+			}
+		}
+
+		if k.dup = f.seen[f.key]; !k.dup {
+			f.seen[f.key] = true
+		} else if k.policy == RejectDuplicates || k.strict {
+			k.dup = false
+			err = &DuplicateKeyError{f.key, k.pointer(), k.litOff}
+		}
+	} else if end == StringEnd && k.inLit {
+		k.inLit = false
+		err = k.checkString()
+	} else if end == NumberEnd && k.inLit {
+		k.inLit = false
+		err = k.checkNumber()
+	}
+
+	if err != nil {
+		if ev = s.reject(err, ev); ev == Error {
+			return Error
+		}
+	}
+
+	if start := ev & Start; start != 0 {
+		if n := len(k.frames); n > 0 && start != KeyStart && k.frames[n-1].array {
+			k.frames[n-1].index++
+		}
+
+		switch start {
+		case ObjectStart:
+			k.frames = append(k.frames, keyFrame{seen: make(map[string]bool)})
+		case ArrayStart:
+			k.frames = append(k.frames, keyFrame{array: true, index: -1})
+		case StringStart, NumberStart:
+			if !k.strict {
+				break
+			}
+			fallthrough
+		case KeyStart:
+			k.lit = append(k.lit[:0], c)
+			k.inLit = true
+			k.litOff = s.off
+		}
+	} else if k.inLit {
+		k.lit = append(k.lit, c)
+	}
+
+	return ev
+}
+
+// reject reports a duplicate key or strict mode violation. Scanning halts
+// unless in recovery mode, in which case the error is only recorded, and
+// the event is passed through: the input is well-formed, so there is
+// nothing to recover from.
+func (s *Scanner) reject(err error, ev Event) Event {
+	if s.recover {
+		s.err = err
+		s.errs = append(s.errs, err)
+		return ev
+	}
+	return s.raise(err)
+}
+
+// pointer returns a JSON Pointer to the current member or element.
+func (k *keyTracker) pointer() string {
+	var buf []byte
+
+	for _, f := range k.frames {
+		buf = append(buf, '/')
+		if f.array {
+			buf = strconv.AppendInt(buf, int64(f.index), 10)
+		} else {
+			buf = appendPointerToken(buf, []byte(f.key))
+		}
+	}
+
+	return string(buf)
+}
@@ -0,0 +1,177 @@
+package jo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var rejectDuplicatesTests = []struct {
+	in  string
+	err string
+}{
+	{`{"a": 1, "b": 2}`, ``},
+	{`[{"a": 1}, {"a": 2}]`, ``},
+	{`{"a": {"a": {"a": null}}}`, ``},
+	{`{"a": 1, "a": 2}`, `duplicate key "a" at "/a" (offset 9)`},
+	{`{"é": 1, "é": 2}`, `duplicate key "é" at "/é" (offset 10)`},
+	{`[0, {"x": [], "y/z": 1, "y\/z": 2}]`, `duplicate key "y/z" at "/1/y~1z" (offset 24)`},
+	{`{"a": {"b": 1}, "c": {"b": 2, "b": 3}}`, `duplicate key "b" at "/c/b" (offset 30)`},
+}
+
+func TestRejectDuplicates(t *testing.T) {
+	for _, test := range rejectDuplicatesTests {
+		var s = NewScanner()
+		var err string
+
+		s.SetDuplicatePolicy(RejectDuplicates)
+
+		for _, c := range []byte(test.in) {
+			if s.Scan(c) == Error {
+				break
+			}
+		}
+		if s.End() == Error {
+			err = s.LastError().Error()
+		}
+
+		if err != test.err {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %q", err)
+			t.Errorf("  want %q", test.err)
+		}
+	}
+}
+
+func TestRejectDuplicatesRecovery(t *testing.T) {
+	var s = NewScanner()
+
+	s.SetDuplicatePolicy(RejectDuplicates)
+	s.SetRecovery(true)
+
+	for _, c := range []byte(`[{"a": 1, "a": 2}, {"b": x, "b": 3, "b": 4}]`) {
+		s.Scan(c)
+	}
+	s.End()
+
+	var got []string
+	for _, err := range s.Errors() {
+		got = append(got, err.Error())
+	}
+
+	want := []string{
+		`duplicate key "a" at "/0/a" (offset 10)`,
+		`invalid character 'x' in place of value start, expected '{', '[', string, number, true, false or null`,
+		`duplicate key "b" at "/1/b" (offset 28)`,
+		`duplicate key "b" at "/1/b" (offset 36)`,
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got  %q", got)
+		t.Errorf("want %q", want)
+	}
+}
+
+func TestRejectDuplicatesRecoveryEvents(t *testing.T) {
+	var in = `{"a": [1], "a": {"b": "c"}, "d": true, "a": null}`
+	var s, plain = NewScanner(), NewScanner()
+
+	s.SetDuplicatePolicy(RejectDuplicates)
+	s.SetRecovery(true)
+
+	// The input is well-formed, so the events must be the same as without
+	// duplicate detection.
+	for i, c := range []byte(in) {
+		if got, want := s.Scan(c), plain.Scan(c); got != want {
+			t.Fatalf("offset %d: got %v, want %v", i, got, want)
+		}
+	}
+	if got, want := s.End(), plain.End(); got != want {
+		t.Fatalf("End: got %v, want %v", got, want)
+	}
+
+	if n := len(s.Errors()); n != 2 {
+		t.Errorf("got %d errors, want 2", n)
+	}
+	if err := s.LastError(); err == nil || err.Error() != `duplicate key "a" at "/a" (offset 39)` {
+		t.Errorf("got last error %v", err)
+	}
+}
+
+var duplicatePolicyTests = []struct {
+	in    string
+	first string
+	last  string
+}{
+	{
+		`{"a": 1, "b": 2}`,
+		`{"a": 1, "b": 2}`,
+		`{"a": 1, "b": 2}`,
+	},
+	{
+		`{"a": 1, "b": 2, "a": 3}`,
+		`{"a": 1, "b": 2}`,
+		`{"a": 3, "b": 2}`,
+	},
+	{
+		`{"a": {"x": 1}, "a": [2], "a": {"y": 3, "y": 4}}`,
+		`{"a": {"x": 1}}`,
+		`{"a": {"y": 4}}`,
+	},
+	{
+		`[{"a": 1, "a": 2}, {"a": 3}]`,
+		`[{"a": 1}, {"a": 3}]`,
+		`[{"a": 2}, {"a": 3}]`,
+	},
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	for _, test := range duplicatePolicyTests {
+		for _, p := range []struct {
+			policy DuplicatePolicy
+			want   string
+		}{
+			{FirstWins, test.first},
+			{LastWins, test.last},
+		} {
+			tz := newTokenizer(strings.NewReader(test.in))
+			tz.s.SetDuplicatePolicy(p.policy)
+
+			got, err := decode(tz)
+			if err != nil {
+				t.Errorf("%#q: %s", test.in, err)
+				continue
+			}
+
+			want, _ := decodeBytes([]byte(p.want))
+			if !equal(got, want) {
+				t.Errorf("%#q (policy %d):", test.in, p.policy)
+				t.Errorf("  got  %v", got)
+				t.Errorf("  want %v", want)
+			}
+		}
+	}
+}
+
+func TestDuplicateTokens(t *testing.T) {
+	var d = NewDecoder(strings.NewReader(`{"a": {"a": 1}, "b": 2, "a": 3}`))
+	var got []string
+
+	d.SetDuplicatePolicy(FirstWins)
+
+	for tok, err := range d.Tokens() {
+		if err != nil {
+			t.Fatalf("Decoder.Tokens yielded %q", err)
+		}
+		if tok.Kind == KeyEnd {
+			got = append(got, fmt.Sprintf("%s %v", d.Pointer(), tok.Duplicate))
+		}
+	}
+
+	want := []string{"/a false", "/a/a false", "/b false", "/a true"}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got  %q", got)
+		t.Errorf("want %q", want)
+	}
+}
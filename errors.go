@@ -0,0 +1,174 @@
+package jo
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// A SyntaxError describes malformed JSON input.
+type SyntaxError struct {
+	// Offset of the offending byte, or of the end of input.
+	Offset int64
+
+	// The offending byte, or -1 if the input ended prematurely.
+	Char int
+
+	// Descriptions of the tokens which would have been acceptable in place
+	// of the offending byte, such as "':'" or "string".
+	Expected []string
+
+	msg string
+}
+
+func (e *SyntaxError) Error() string {
+	if len(e.Expected) == 0 {
+		return e.msg
+	}
+	return e.msg + ", expected " + list(e.Expected)
+}
+
+// list joins a set of alternatives as in "a, b or c".
+func list(items []string) string {
+	n := len(items)
+	if n < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:n-1], ", ") + " or " + items[n-1]
+}
+
+// Tokens expected by the various state functions.
+var (
+	expectValue = []string{`'{'`, `'['`, `string`, `number`, `true`, `false`, `null`}
+	expectDigit = []string{`digit`}
+	expectHex   = []string{`hexadecimal digit`}
+)
+
+// expectations maps the entry points of state functions to the tokens they
+// accept. It is populated by init, as the state functions refer to it.
+var expectations = make(map[uintptr][]string)
+
+func init() {
+	for _, e := range []struct {
+		fn     func(*Scanner, byte) Event
+		tokens []string
+	}{
+		{beforeValue, expectValue},
+		{betweenLines, expectValue},
+		{beforeFirstObjectKey, []string{`string`, `'}'`}},
+		{afterObjectKey, []string{`':'`}},
+		{afterObjectValue, []string{`','`, `'}'`}},
+		{afterObjectComma, []string{`string`}},
+		{beforeFirstArrayElement, append(expectValue[:len(expectValue):len(expectValue)], `']'`)},
+		{afterArrayElement, []string{`','`, `']'`}},
+		{afterQuote, []string{`'"'`, `'\\'`, `non-control character`}},
+		{afterEsc, []string{`'"'`, `'\\'`, `'/'`, `'b'`, `'f'`, `'n'`, `'r'`, `'t'`, `'u'`}},
+		{afterEscU, expectHex},
+		{afterEscU1, expectHex},
+		{afterEscU12, expectHex},
+		{afterEscU123, expectHex},
+		{afterMinus, expectDigit},
+		{afterZero, []string{`'.'`, `'e'`, `'E'`}},
+		{afterDigit, []string{`digit`, `'.'`, `'e'`, `'E'`}},
+		{afterDot, expectDigit},
+		{afterDotDigit, []string{`digit`, `'e'`, `'E'`}},
+		{afterE, []string{`digit`, `'+'`, `'-'`}},
+		{afterESign, expectDigit},
+		{afterEDigit, expectDigit},
+		{afterT, []string{`'r'`}},
+		{afterTr, []string{`'u'`}},
+		{afterTru, []string{`'e'`}},
+		{afterF, []string{`'a'`}},
+		{afterFa, []string{`'l'`}},
+		{afterFal, []string{`'s'`}},
+		{afterFals, []string{`'e'`}},
+		{afterN, []string{`'u'`}},
+		{afterNu, []string{`'l'`}},
+		{afterNul, []string{`'l'`}},
+		{afterTopValue, []string{`end of input`}},
+	} {
+		expectations[reflect.ValueOf(e.fn).Pointer()] = e.tokens
+	}
+}
+
+// expected returns the tokens accepted by a state function.
+func expected(fn func(*Scanner, byte) Event) []string {
+	return expectations[reflect.ValueOf(fn).Pointer()]
+}
+
+// expected returns the tokens accepted in the Scanner's current state,
+// preceded by those which could have continued a number literal just ended.
+func (s *Scanner) expected() []string {
+	if s.number == nil {
+		return expected(s.state)
+	}
+	return append(append([]string{}, expected(s.number)...), expected(s.state)...)
+}
+
+// Excerpt renders the line of src containing the byte at offset, followed by
+// a line with a caret pointing at that byte. Long lines are trimmed to show
+// at most 32 characters on either side of the offset.
+//
+// For example, Excerpt([]byte(`{"a" 1}`), 5) returns:
+//
+//	{"a" 1}
+//	     ^
+func Excerpt(src []byte, offset int64) string {
+	const context = 32
+
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(src)) {
+		offset = int64(len(src))
+	}
+
+	// Find the boundaries of the line.
+	start := int(offset)
+	for start > 0 && src[start-1] != '\n' {
+		start--
+	}
+
+	end := int(offset)
+	for end < len(src) && src[end] != '\n' {
+		end++
+	}
+
+	before := src[start:offset]
+	after := src[offset:end]
+
+	var prefix, suffix string
+
+	if utf8.RuneCount(before) > context {
+		for utf8.RuneCount(before) > context-3 {
+			_, n := utf8.DecodeRune(before)
+			before = before[n:]
+		}
+		prefix = "..."
+	}
+	if utf8.RuneCount(after) > context {
+		for utf8.RuneCount(after) > context-3 {
+			_, n := utf8.DecodeLastRune(after)
+			after = after[:len(after)-n]
+		}
+		suffix = "..."
+	}
+
+	// Line up the caret, preserving tabs so that it also lines up when the
+	// excerpt is printed to a terminal.
+	var caret []byte
+
+	for range prefix {
+		caret = append(caret, ' ')
+	}
+	for _, r := range string(before) {
+		if r == '\t' {
+			caret = append(caret, '\t')
+		} else {
+			caret = append(caret, ' ')
+		}
+	}
+
+	line := strings.TrimRight(prefix+string(before)+string(after)+suffix, "\r")
+	return line + "\n" + string(caret) + "^"
+}
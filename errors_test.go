@@ -0,0 +1,157 @@
+package jo
+
+import (
+	"testing"
+)
+
+var syntaxErrorTests = []struct {
+	in     string
+	offset int64
+	char   int
+	msg    string
+}{
+	{
+		`x`,
+		0, 'x',
+		`invalid character 'x' in place of value start, expected '{', '[', string, number, true, false or null`,
+	},
+	{
+		`[`,
+		1, -1,
+		`unexpected end of JSON input, expected '{', '[', string, number, true, false, null or ']'`,
+	},
+	{
+		`{"a" 1}`,
+		5, '1',
+		`invalid character '1' after object key, expected ':'`,
+	},
+	{
+		`{"a":1 "b"}`,
+		7, '"',
+		`invalid character '"' after object value, expected ',' or '}'`,
+	},
+	{
+		`"\x"`,
+		2, 'x',
+		`invalid character 'x' in character escape, expected '"', '\\', '/', 'b', 'f', 'n', 'r', 't' or 'u'`,
+	},
+	{
+		`"\u12`,
+		5, -1,
+		`unexpected end of JSON input, expected hexadecimal digit`,
+	},
+	{
+		"\"a\x01\"",
+		2, 1,
+		`invalid character '\x01' in string literal, expected '"', '\\' or non-control character`,
+	},
+	{
+		`1.e5`,
+		2, 'e',
+		`invalid character 'e' after decimal point in numeric literal, expected digit`,
+	},
+	{
+		`1e`,
+		2, -1,
+		`unexpected end of JSON input, expected digit, '+' or '-'`,
+	},
+	{
+		`tr`,
+		2, -1,
+		`unexpected end of JSON input, expected 'u'`,
+	},
+	{
+		`nulL`,
+		3, 'L',
+		`invalid character 'L' after "nul", expected 'l'`,
+	},
+	{
+		`[] []`,
+		3, '[',
+		`invalid character '[' after top-level value, expected end of input`,
+	},
+	{
+		`[1,2`,
+		4, -1,
+		`unexpected end of JSON input, expected digit, '.', 'e', 'E', ',' or ']'`,
+	},
+	{
+		`{"a": 0x}`,
+		7, 'x',
+		`invalid character 'x' after object value, expected '.', 'e', 'E', ',' or '}'`,
+	},
+	{
+		`[-1.5`,
+		5, -1,
+		`unexpected end of JSON input, expected digit, 'e', 'E', ',' or ']'`,
+	},
+	{
+		`1e5x`,
+		3, 'x',
+		`invalid character 'x' after top-level value, expected digit or end of input`,
+	},
+}
+
+func TestSyntaxError(t *testing.T) {
+	for _, test := range syntaxErrorTests {
+		var s = NewScanner()
+		var ev Event
+
+		for i := 0; i < len(test.in) && ev != Error; i++ {
+			ev = s.Scan(test.in[i])
+		}
+		if ev != Error {
+			ev = s.End()
+		}
+
+		err, ok := s.LastError().(*SyntaxError)
+		if ev != Error || !ok {
+			t.Errorf("Scanner(%#q) did not return a *SyntaxError", test.in)
+			continue
+		}
+
+		if err.Offset != test.offset || err.Char != test.char || err.Error() != test.msg {
+			t.Errorf("Scanner(%#q):", test.in)
+			t.Errorf("  got  %d, %d, %#q", err.Offset, err.Char, err.Error())
+			t.Errorf("  want %d, %d, %#q", test.offset, test.char, test.msg)
+		}
+	}
+}
+
+var excerptTests = []struct {
+	in     string
+	offset int64
+	out    string
+}{
+	{
+		`{"a" 1}`, 5,
+		"{\"a\" 1}\n" +
+			"     ^",
+	},
+	{
+		"[\n\t\"é\" 1\r\n]", 8,
+		"\t\"é\" 1\n" +
+			"\t    ^",
+	},
+	{
+		"[1,\n", 4,
+		"\n" +
+			"^",
+	},
+	{
+		`["aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"]`, 44,
+		`...aaaaaaaaaaaaaaaaaaaaaaaaaaa" "bbbbbbbbbbbbbbbbbbbbbbbbbbbb...` + "\n" +
+			`                                ^`,
+	},
+}
+
+func TestExcerpt(t *testing.T) {
+	for _, test := range excerptTests {
+		out := Excerpt([]byte(test.in), test.offset)
+		if out != test.out {
+			t.Errorf("Excerpt(%#q, %d):", test.in, test.offset)
+			t.Errorf("  got  %q", out)
+			t.Errorf("  want %q", test.out)
+		}
+	}
+}
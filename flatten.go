@@ -0,0 +1,300 @@
+package jo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Flatten reads a single document from src and writes it to dst as a list
+// of assignments, one per line, in the style of gron:
+//
+//	json = {};
+//	json.users = [];
+//	json.users[0] = {};
+//	json.users[0].name = "Ann";
+//
+// Every value gets a line of its own, objects and arrays being represented
+// by empty ones. Keys which are valid identifiers are written after a dot,
+// others as quoted strings in brackets. Strings and numbers are copied
+// verbatim. Because each line holds a complete path, the output can be
+// filtered with line-based tools like grep and turned back into JSON with
+// Unflatten.
+func Flatten(dst io.Writer, src io.Reader) error {
+	var w = bufio.NewWriter(dst)
+	var t = newTokenizer(src)
+	var buf []byte
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Flush()
+			return err
+		}
+
+		buf = append(buf[:0], "json"...)
+		for _, f := range t.stack {
+			buf = appendPathElem(buf, f)
+		}
+		buf = append(buf, " = "...)
+
+		switch tok.kind {
+		case KeyEnd, ObjectEnd, ArrayEnd:
+			continue
+		case ObjectStart:
+			buf = append(buf, "{}"...)
+		case ArrayStart:
+			buf = append(buf, "[]"...)
+		default:
+			buf = append(buf, tok.text...)
+		}
+
+		buf = append(buf, ";\n"...)
+		w.Write(buf)
+	}
+
+	return w.Flush()
+}
+
+// appendPathElem appends the accessor for the current member or element of
+// an enclosing object or array to dst.
+func appendPathElem(dst []byte, f frame) []byte {
+	if f.array {
+		dst = append(dst, '[')
+		dst = strconv.AppendInt(dst, int64(f.index), 10)
+		return append(dst, ']')
+	}
+
+	if isIdentifier(f.key) {
+		return append(append(dst, '.'), f.key...)
+	}
+
+	dst = append(dst, '[')
+	dst = appendQuote(dst, f.key)
+	return append(dst, ']')
+}
+
+// isIdentifier reports whether s is a non-empty sequence of ASCII letters,
+// digits, underscores and dollar signs, not starting with a digit.
+func isIdentifier(s []byte) bool {
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == '$':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// Unflatten reads assignments in the format written by Flatten from src,
+// and writes the document they describe to dst as compact JSON.
+//
+// The lines may come in any order, and some may be missing. Objects and
+// arrays are created as needed to hold the values assigned to paths within
+// them, and gaps left in arrays are filled with nulls, up to a total of
+// maxUnflattenGap. If the same path is assigned more than once, the last
+// value wins. Blank lines are ignored, but there must be at least one
+// assignment.
+func Unflatten(dst io.Writer, src io.Reader) error {
+	var r = bufio.NewReader(src)
+	var doc interface{}
+	var empty = true
+	var gap = maxUnflattenGap
+
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			path, val, perr := parseAssignment(line)
+			if perr != nil {
+				return fmt.Errorf("jo: line %d: %v", n, perr)
+			}
+			if doc, perr = assign(doc, path, val, &gap); perr != nil {
+				return fmt.Errorf("jo: line %d: %v", n, perr)
+			}
+			empty = false
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if empty {
+		return fmt.Errorf("jo: no assignments")
+	}
+
+	w := newWriter(dst)
+	w.value(doc)
+	return w.flush()
+}
+
+// Unflatten fills no more than this many gaps in arrays with nulls, so that
+// a single line assigning to a huge index can't exhaust memory.
+const maxUnflattenGap = 1 << 20
+
+// A pathElem is a key or an index in a path parsed by Unflatten.
+type pathElem struct {
+	key   string
+	index int
+	array bool
+}
+
+// parseAssignment parses a single line written by Flatten.
+func parseAssignment(line []byte) ([]pathElem, interface{}, error) {
+	line = bytes.TrimSpace(line)
+
+	if !bytes.HasPrefix(line, []byte("json")) {
+		return nil, nil, fmt.Errorf("expected path starting with \"json\"")
+	}
+
+	var path []pathElem
+	var i = len("json")
+
+	for i < len(line) && (line[i] == '.' || line[i] == '[') {
+		if line[i] == '.' {
+			j := bytes.IndexAny(line[i+1:], ".[ =")
+			if j < 0 {
+				j = len(line)
+			} else {
+				j += i + 1
+			}
+			if !isIdentifier(line[i+1 : j]) {
+				return nil, nil, fmt.Errorf("invalid key at column %d", i+2)
+			}
+			path = append(path, pathElem{key: string(line[i+1 : j])})
+			i = j
+			continue
+		}
+
+		var elem pathElem
+		var j = i + 1
+
+		if j < len(line) && line[j] == '"' {
+			// Find the closing quote, then let the decoder validate the
+			// literal.
+			for j++; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return nil, nil, fmt.Errorf("unterminated key at column %d", i+2)
+			}
+			j++
+
+			v, err := decodeBytes(line[i+1 : j])
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid key at column %d", i+2)
+			}
+			elem.key = v.(string)
+		} else {
+			for j < len(line) && line[j] >= '0' && line[j] <= '9' {
+				j++
+			}
+			n, err := strconv.Atoi(string(line[i+1 : j]))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid index at column %d", i+2)
+			}
+			elem.index = n
+			elem.array = true
+		}
+
+		if j >= len(line) || line[j] != ']' {
+			return nil, nil, fmt.Errorf("expected ']' at column %d", j+1)
+		}
+
+		path = append(path, elem)
+		i = j + 1
+	}
+
+	rest := bytes.TrimSpace(line[i:])
+	if len(rest) == 0 || rest[0] != '=' {
+		return nil, nil, fmt.Errorf("expected '=' at column %d", len(line)-len(rest)+1)
+	}
+
+	rest = bytes.TrimSpace(bytes.TrimSuffix(rest[1:], []byte(";")))
+
+	val, err := decodeBytes(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return path, val, nil
+}
+
+// assign stores val at the given path within v, and returns the updated
+// value. Objects and arrays along the way are created or replaced as
+// needed. Assigning an empty object or array to an existing one of the
+// same kind leaves it intact, so that the order of lines doesn't matter.
+// Nulls filling gaps in arrays are counted against *gap.
+func assign(v interface{}, path []pathElem, val interface{}, gap *int) (interface{}, error) {
+	if len(path) == 0 {
+		switch val := val.(type) {
+		case object:
+			if o, ok := v.(object); ok && len(val) == 0 {
+				return o, nil
+			}
+		case []interface{}:
+			if a, ok := v.([]interface{}); ok && len(val) == 0 {
+				return a, nil
+			}
+		}
+		return val, nil
+	}
+
+	e := path[0]
+
+	if e.array {
+		a, ok := v.([]interface{})
+		if !ok {
+			a = []interface{}{}
+		}
+		if e.index > len(a) {
+			if *gap -= e.index - len(a); *gap < 0 {
+				return nil, fmt.Errorf("index %d out of range", e.index)
+			}
+		}
+		for len(a) <= e.index {
+			a = append(a, nil)
+		}
+
+		elem, err := assign(a[e.index], path[1:], val, gap)
+		if err != nil {
+			return nil, err
+		}
+		a[e.index] = elem
+		return a, nil
+	}
+
+	o, ok := v.(object)
+	if !ok {
+		o = object{}
+	}
+
+	for i := range o {
+		if o[i].key == e.key {
+			elem, err := assign(o[i].val, path[1:], val, gap)
+			if err != nil {
+				return nil, err
+			}
+			o[i].val = elem
+			return o, nil
+		}
+	}
+
+	elem, err := assign(nil, path[1:], val, gap)
+	if err != nil {
+		return nil, err
+	}
+	return append(o, member{e.key, elem}), nil
+}
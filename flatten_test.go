@@ -0,0 +1,172 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var flattenTests = []struct {
+	in  string
+	out string
+	err string
+}{
+	{
+		`{"users": [{"name": "Ann", "age": 31}, {"name": "B\u00f6b", "tags": []}]}`,
+		"json = {};\n" +
+			"json.users = [];\n" +
+			"json.users[0] = {};\n" +
+			"json.users[0].name = \"Ann\";\n" +
+			"json.users[0].age = 31;\n" +
+			"json.users[1] = {};\n" +
+			"json.users[1].name = \"B\\u00f6b\";\n" +
+			"json.users[1].tags = [];\n",
+		``,
+	},
+	{
+		`{"a b": {"1x": null, "$_y2": true}, "": 1e3, "q\"": "\n"}`,
+		"json = {};\n" +
+			"json[\"a b\"] = {};\n" +
+			"json[\"a b\"][\"1x\"] = null;\n" +
+			"json[\"a b\"].$_y2 = true;\n" +
+			"json[\"\"] = 1e3;\n" +
+			"json[\"q\\\"\"] = \"\\n\";\n",
+		``,
+	},
+	{
+		`-0.5`,
+		"json = -0.5;\n",
+		``,
+	},
+	{
+		`[1, [2,`,
+		"json = [];\n" +
+			"json[0] = 1;\n" +
+			"json[1] = [];\n" +
+			"json[1][0] = 2;\n",
+		`unexpected end of JSON input, expected '{', '[', string, number, true, false or null`,
+	},
+}
+
+func TestFlatten(t *testing.T) {
+	for _, test := range flattenTests {
+		var buf bytes.Buffer
+		var msg string
+
+		if err := Flatten(&buf, strings.NewReader(test.in)); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || buf.String() != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %q, %q", buf.String(), msg)
+			t.Errorf("  want %q, %q", test.out, test.err)
+		}
+	}
+}
+
+var unflattenTests = []struct {
+	in  string
+	out string
+	err string
+}{
+	{
+		"json = {};\n" +
+			"json.users = [];\n" +
+			"json.users[0] = {};\n" +
+			"json.users[0].name = \"Ann\";\n" +
+			"json.users[0].age = 31;\n",
+		`{"users":[{"name":"Ann","age":31}]}`,
+		``,
+	},
+	{
+		// Output of grep "name".
+		"json.users[0].name = \"Ann\";\n" +
+			"json.users[2].name = \"Cy\";\n",
+		`{"users":[{"name":"Ann"},null,{"name":"Cy"}]}`,
+		``,
+	},
+	{
+		"json[\"a b\"][\"q\\\"\"] = [];\n" +
+			"\n" +
+			"json[\"a b\"] = {};\n" +
+			"  json.x = 1.50 ;\n" +
+			"json.x = 2",
+		`{"a b":{"q\"":[]},"x":2}`,
+		``,
+	},
+	{
+		"json = \"x\";\n",
+		`"x"`,
+		``,
+	},
+	{
+		"",
+		``,
+		`jo: no assignments`,
+	},
+	{
+		"\n  \n",
+		``,
+		`jo: no assignments`,
+	},
+	{
+		"json[50000000] = 1;\n",
+		``,
+		`jo: line 1: index 50000000 out of range`,
+	},
+	{
+		// The gaps are limited in total, not per array.
+		"json.a[1048576] = 1;\n" +
+			"json.b[1] = 2;\n",
+		``,
+		`jo: line 2: index 1 out of range`,
+	},
+	{
+		"json = {};\nusers = [];\n",
+		``,
+		`jo: line 2: expected path starting with "json"`,
+	},
+	{
+		"json.a-b = 1;\n",
+		``,
+		`jo: line 1: invalid key at column 6`,
+	},
+	{
+		"json[x] = 1;\n",
+		``,
+		`jo: line 1: invalid index at column 6`,
+	},
+	{
+		"json[\"a] = 1;\n",
+		``,
+		`jo: line 1: unterminated key at column 6`,
+	},
+	{
+		"json.a 1;\n",
+		``,
+		`jo: line 1: expected '=' at column 8`,
+	},
+	{
+		"json.a = {\"b\": 1};\n",
+		`{"a":{"b":1}}`,
+		``,
+	},
+}
+
+func TestUnflatten(t *testing.T) {
+	for _, test := range unflattenTests {
+		var buf bytes.Buffer
+		var msg string
+
+		if err := Unflatten(&buf, strings.NewReader(test.in)); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || msg == "" && buf.String() != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %q, %q", buf.String(), msg)
+			t.Errorf("  want %q, %q", test.out, test.err)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package jo
+
+import (
+	"reflect"
+)
+
+// A Guide scans a document while keeping track of the schema which applies
+// at each point, so that it can narrow the set of bytes allowed next down
+// to those leading to values of the expected types and to the keys the
+// schema allows. This makes it suitable for constraining the generation of
+// JSON text to a schema.
+//
+// The narrowing is based on the type, properties, additionalProperties,
+// required and items keywords. Guides err on the side of caution, and may
+// rule out some valid spellings, such as 1.0 for an integer or escape
+// sequences in keys. Bytes which are ruled out are still accepted by Scan.
+type Guide struct {
+	s      *Scanner
+	schema *Schema
+
+	// Enclosing objects and arrays.
+	frames []guideFrame
+
+	// Raw text of the key being scanned.
+	key   []byte
+	inKey bool
+}
+
+// A guideFrame describes an object or array being scanned by a Guide.
+type guideFrame struct {
+	// Schema applying to the object or array, if any.
+	schema *Schema
+	array  bool
+
+	// Keys seen so far, and the current member's key.
+	keys map[string]bool
+	key  string
+}
+
+// NewGuide returns a Guide for documents conforming to the schema.
+func NewGuide(sc *Schema) *Guide {
+	return &Guide{s: NewScanner(), schema: sc}
+}
+
+// Scan accepts a byte of input and returns an Event, just as a Scanner's
+// Scan method does.
+func (g *Guide) Scan(c byte) Event {
+	ev := g.s.Scan(c)
+	if ev == Error {
+		return ev
+	}
+
+	if end := ev & End; end == ObjectEnd || end == ArrayEnd {
+		g.frames = g.frames[:len(g.frames)-1]
+	} else if end == KeyEnd {
+		f := &g.frames[len(g.frames)-1]
+		f.key = string(unquote(nil, g.key))
+		f.keys[f.key] = true
+		g.inKey = false
+	}
+
+	switch ev & Start {
+	case ObjectStart:
+		g.frames = append(g.frames, guideFrame{schema: g.current(), keys: make(map[string]bool)})
+	case ArrayStart:
+		g.frames = append(g.frames, guideFrame{schema: g.current(), array: true})
+	case KeyStart:
+		g.key = append(g.key[:0], c)
+		g.inKey = true
+	case None:
+		if g.inKey {
+			g.key = append(g.key, c)
+		}
+	}
+
+	return ev
+}
+
+// End signals the end of input, and returns an Event just as a Scanner's End
+// method does.
+func (g *Guide) End() Event {
+	return g.s.End()
+}
+
+// LastError returns the error behind the last Error event.
+func (g *Guide) LastError() error {
+	return g.s.LastError()
+}
+
+// Clone returns an independent copy of the Guide.
+func (g *Guide) Clone() *Guide {
+	c := *g
+	c.s = g.s.Clone()
+	c.frames = make([]guideFrame, len(g.frames))
+	c.key = append([]byte{}, g.key...)
+
+	for i, f := range g.frames {
+		if f.keys != nil {
+			keys := make(map[string]bool, len(f.keys))
+			for key := range f.keys {
+				keys[key] = true
+			}
+			f.keys = keys
+		}
+		c.frames[i] = f
+	}
+
+	return &c
+}
+
+// CanEnd reports whether the input scanned so far forms a complete document.
+func (g *Guide) CanEnd() bool {
+	return g.s.CanEnd()
+}
+
+// Allowed returns the set of bytes which are allowed next, according to
+// both the JSON grammar and the schema.
+func (g *Guide) Allowed() [256]bool {
+	set := g.s.Allowed()
+
+	// Bytes not accepted by the current state may be handed to the next
+	// one, as with a number followed by a comma.
+	st, next := g.s.state, g.s.state
+	if n := len(g.s.stack); n > 0 && popping[reflect.ValueOf(st).Pointer()] {
+		next = g.s.stack[n-1]
+	}
+
+	// An object or array which has ended, but whose end event is still
+	// pending, has yet to be popped.
+	frames := g.frames
+	if same(st, delayed) && (g.s.end == ObjectEnd || g.s.end == ArrayEnd) {
+		frames = frames[:len(frames)-1]
+	}
+
+	switch {
+	case same(st, beforeValue), same(st, beforeFirstArrayElement), same(st, betweenLines):
+		sc := g.current()
+		if sc == nil {
+			break
+		}
+		for c := range set {
+			if f := startType[c]; f != 0 && (sc.never || !sc.allows(f)) {
+				set[c] = false
+			}
+		}
+
+	case same(st, afterZero), same(st, afterDigit):
+		if sc := g.current(); sc != nil && sc.types&typeInteger != 0 && sc.types&typeNumber == 0 {
+			set['.'], set['e'], set['E'] = false, false, false
+		}
+
+	case same(st, afterQuote), same(st, afterEsc), same(st, afterEscU),
+		same(st, afterEscU1), same(st, afterEscU12), same(st, afterEscU123):
+		if !g.inKey {
+			break
+		}
+		f := &frames[len(frames)-1]
+		if f.schema == nil || !f.closed() {
+			break
+		}
+
+		// Only allow bytes continuing the name of an unused property, as
+		// written without unnecessary escape sequences.
+		var ok [256]bool
+		for name, sub := range f.schema.properties {
+			if f.keys[name] || sub.never {
+				continue
+			}
+			q := appendQuote(nil, []byte(name))
+			if len(q) > len(g.key) && string(q[:len(g.key)]) == string(g.key) {
+				ok[q[len(g.key)]] = true
+			}
+		}
+		for c := range set {
+			set[c] = set[c] && ok[c]
+		}
+	}
+
+	// Objects can't end before all required properties have been seen, nor
+	// go on after all properties have been seen if others aren't allowed.
+	if same(st, beforeFirstObjectKey) || same(st, afterObjectComma) || same(next, afterObjectValue) {
+		f := &frames[len(frames)-1]
+		if f.schema == nil {
+			return set
+		}
+		if !f.complete() {
+			set['}'] = false
+		}
+		if f.closed() && f.unused() == 0 {
+			set['"'] = false
+			set[','] = false
+		}
+	}
+
+	return set
+}
+
+// current returns the schema applying to the value about to be scanned, or
+// being scanned, if any.
+func (g *Guide) current() *Schema {
+	n := len(g.frames)
+	if n == 0 {
+		return g.schema
+	}
+
+	f := &g.frames[n-1]
+	if f.schema == nil {
+		return nil
+	}
+	if f.array {
+		return f.schema.items
+	}
+	if sub, ok := f.schema.properties[f.key]; ok {
+		return sub
+	}
+	return f.schema.additional
+}
+
+// closed reports whether the object's schema disallows properties other
+// than those it lists.
+func (f *guideFrame) closed() bool {
+	add := f.schema.additional
+	return add != nil && add.never
+}
+
+// unused returns the number of listed properties not yet seen, and not
+// ruled out by their own schemas.
+func (f *guideFrame) unused() int {
+	n := 0
+	for name, sub := range f.schema.properties {
+		if !f.keys[name] && !sub.never {
+			n++
+		}
+	}
+	return n
+}
+
+// complete reports whether all required properties have been seen.
+func (f *guideFrame) complete() bool {
+	for _, name := range f.schema.required {
+		if !f.keys[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// allows reports whether the schema allows values of the given type.
+func (sc *Schema) allows(flag int) bool {
+	types := sc.types
+	if types&typeNumber != 0 {
+		types |= typeInteger
+	}
+	return types == 0 || types&flag != 0
+}
+
+// startType maps bytes which can start a value to the type of that value.
+var startType [256]int
+
+func init() {
+	startType['{'] = typeObject
+	startType['['] = typeArray
+	startType['"'] = typeString
+	startType['t'] = typeBoolean
+	startType['f'] = typeBoolean
+	startType['n'] = typeNull
+	startType['-'] = typeNumber | typeInteger
+
+	for c := '0'; c <= '9'; c++ {
+		startType[c] = typeNumber | typeInteger
+	}
+}
@@ -0,0 +1,110 @@
+package jo
+
+import (
+	"testing"
+)
+
+var guideSchema = `{
+	"type": "object",
+	"properties": {
+		"id":   {"type": "integer"},
+		"name": {"type": "string"},
+		"nick": {"type": "string"},
+		"tags": {"type": "array", "items": {"type": ["string", "null"]}},
+		"no":   false
+	},
+	"required": ["id"],
+	"additionalProperties": false
+}`
+
+var guideTests = []struct {
+	in   string
+	want string // printable ASCII bytes allowed next, excluding whitespace
+}{
+	{``, `{`},
+	{`{`, `"`},
+	{`{"`, `int`},
+	{`{"n`, `ai`},
+	{`{"na`, `m`},
+	{`{"name`, `"`},
+	{`{"name"`, `:`},
+	{`{"name":`, `"`},
+	{`{"name": "x"`, `,`},
+	{`{"name": "x",`, `"`},
+	{`{"name": "x", "`, `int`},
+	{`{"name": "x", "n`, `i`},
+	{`{"name": "x", "id": `, `-0123456789`},
+	{`{"name": "x", "id": 1`, `,0123456789}`},
+	{`{"name": "x", "id": 0`, `,}`},
+	{`{"name": "x", "id": 12}`, ``},
+	{`{"id": 1, "tags": `, `[`},
+	{`{"id": 1, "tags": [`, `"]n`},
+	{`{"id": 1, "tags": ["a"`, `,]`},
+	{`{"id": 1, "tags": ["a", `, `"n`},
+	{`{"id": 1, "tags": []`, `,}`},
+	{`{"id": 1, "tags": [], "name": "", "nick": ""`, `}`},
+}
+
+func TestGuide(t *testing.T) {
+	sc, err := CompileSchema([]byte(guideSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range guideTests {
+		g := NewGuide(sc)
+		for i := 0; i < len(test.in); i++ {
+			if g.Scan(test.in[i]) == Error {
+				t.Fatalf("%#q: unexpected error: %v", test.in, g.LastError())
+			}
+		}
+
+		// Cloning must not make a difference.
+		g = g.Clone()
+
+		var got []byte
+		set := g.Allowed()
+
+		for c := byte('!'); c <= '~'; c++ {
+			if set[c] {
+				got = append(got, c)
+			}
+		}
+
+		if string(got) != test.want {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %#q", got)
+			t.Errorf("  want %#q", test.want)
+		}
+	}
+}
+
+func TestGuideUnconstrained(t *testing.T) {
+	sc, err := CompileSchema([]byte(`{"properties": {"a": {"type": "boolean"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a schema for the value, the Guide allows what the Scanner
+	// does.
+	for _, in := range []string{``, `{`, `{"b": `, `{"b": [`, `{"b": 1`, `{"z`} {
+		g := NewGuide(sc)
+		s := NewScanner()
+		for i := 0; i < len(in); i++ {
+			g.Scan(in[i])
+			s.Scan(in[i])
+		}
+
+		if g.Allowed() != s.Allowed() {
+			t.Errorf("%#q: Guide and Scanner disagree", in)
+		}
+	}
+
+	g := NewGuide(sc)
+	for _, c := range []byte(`{"a": `) {
+		g.Scan(c)
+	}
+	if set := g.Allowed(); set['1'] || set['"'] || !set['t'] || !set['f'] {
+		t.Errorf(`{"a": : expected only booleans to be allowed`)
+	}
+}
@@ -0,0 +1,91 @@
+package jo
+
+import (
+	"errors"
+	"io"
+)
+
+// A Handler receives a callback for each element of a document walked by
+// Walk. Keys and strings are passed in unescaped form, numbers exactly as
+// they appear in the input. Byte slices are only valid until the callback
+// returns.
+//
+// If a callback returns an error, Walk stops and returns that error (or
+// nil, if the error is Stop).
+type Handler interface {
+	OnObjectStart() error
+	OnKey(key []byte) error
+	OnObjectEnd() error
+
+	OnArrayStart() error
+	OnArrayEnd() error
+
+	OnString(str []byte) error
+	OnNumber(num []byte) error
+	OnBool(b bool) error
+	OnNull() error
+}
+
+// Stop can be returned by Handler callbacks to end a walk early without
+// causing Walk to return an error.
+var Stop = errors.New("jo: stop")
+
+// NopHandler implements every Handler callback as a no-op. It is intended
+// to be embedded in handlers only interested in some of the callbacks.
+type NopHandler struct{}
+
+func (NopHandler) OnObjectStart() error  { return nil }
+func (NopHandler) OnKey([]byte) error    { return nil }
+func (NopHandler) OnObjectEnd() error    { return nil }
+func (NopHandler) OnArrayStart() error   { return nil }
+func (NopHandler) OnArrayEnd() error     { return nil }
+func (NopHandler) OnString([]byte) error { return nil }
+func (NopHandler) OnNumber([]byte) error { return nil }
+func (NopHandler) OnBool(bool) error     { return nil }
+func (NopHandler) OnNull() error         { return nil }
+
+// Walk scans a single document from r, invoking the Handler's callbacks in
+// document order. It returns the first syntax error, I/O error or error
+// returned by a callback.
+func Walk(r io.Reader, h Handler) error {
+	var t = newTokenizer(r)
+	var str []byte
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		switch tok.kind {
+		case ObjectStart:
+			err = h.OnObjectStart()
+		case KeyEnd:
+			str = unquote(str[:0], tok.text)
+			err = h.OnKey(str)
+		case ObjectEnd:
+			err = h.OnObjectEnd()
+		case ArrayStart:
+			err = h.OnArrayStart()
+		case ArrayEnd:
+			err = h.OnArrayEnd()
+		case StringEnd:
+			str = unquote(str[:0], tok.text)
+			err = h.OnString(str)
+		case NumberEnd:
+			err = h.OnNumber(tok.text)
+		case BoolEnd:
+			err = h.OnBool(tok.text[0] == 't')
+		case NullEnd:
+			err = h.OnNull()
+		}
+
+		if err == Stop {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package jo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// A recorder is a Handler which records all callbacks, optionally
+// returning an error once a certain number of callbacks have been made.
+type recorder struct {
+	calls []string
+	limit int
+	err   error
+}
+
+func (r *recorder) record(format string, args ...interface{}) error {
+	r.calls = append(r.calls, fmt.Sprintf(format, args...))
+	if len(r.calls) == r.limit {
+		return r.err
+	}
+	return nil
+}
+
+func (r *recorder) OnObjectStart() error      { return r.record("{") }
+func (r *recorder) OnKey(key []byte) error    { return r.record("key %q", key) }
+func (r *recorder) OnObjectEnd() error        { return r.record("}") }
+func (r *recorder) OnArrayStart() error       { return r.record("[") }
+func (r *recorder) OnArrayEnd() error         { return r.record("]") }
+func (r *recorder) OnString(str []byte) error { return r.record("string %q", str) }
+func (r *recorder) OnNumber(num []byte) error { return r.record("number %s", num) }
+func (r *recorder) OnBool(b bool) error       { return r.record("bool %t", b) }
+func (r *recorder) OnNull() error             { return r.record("null") }
+
+var walkTests = []struct {
+	in    string
+	limit int
+	err   error
+	calls string
+	out   string
+}{
+	{
+		`{"a\n": [1, "x\"", [true], {}], "b": {"c": null}, "d": false}`,
+		0, nil,
+		`{, key "a\n", [, number 1, string "x\"", [, bool true, ], {, }, ], ` +
+			`key "b", {, key "c", null, }, key "d", bool false, }`,
+		``,
+	},
+	{
+		`[[1.5e3]]`,
+		0, nil,
+		`[, [, number 1.5e3, ], ]`,
+		``,
+	},
+	{
+		`{"a": 1, "b": 2}`,
+		3, Stop,
+		`{, key "a", number 1`,
+		``,
+	},
+	{
+		`[1, 2, 3]`,
+		2, errors.New("boom"),
+		`[, number 1`,
+		`boom`,
+	},
+	{
+		`[1, 2 3]`,
+		0, nil,
+		`[, number 1, number 2`,
+		`invalid character '3' after array element, expected ',' or ']'`,
+	},
+}
+
+func TestWalk(t *testing.T) {
+	for _, test := range walkTests {
+		var r = &recorder{limit: test.limit, err: test.err}
+		var out string
+
+		if err := Walk(strings.NewReader(test.in), r); err != nil {
+			out = err.Error()
+		}
+
+		calls := strings.Join(r.calls, ", ")
+		if calls != test.calls || out != test.out {
+			t.Errorf("Walk(%#q):", test.in)
+			t.Errorf("  got  %s (%q)", calls, out)
+			t.Errorf("  want %s (%q)", test.calls, test.out)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package jo
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// SetStrict enables or disables strict mode, in which the Scanner enforces
+// the Internet JSON (I-JSON) profile defined in RFC 7493:
+//
+//   - strings and keys must be valid UTF-8, and must not contain escaped
+//     lone surrogates or Unicode noncharacters;
+//   - objects must not contain duplicate keys;
+//   - numbers must be representable as IEEE 754 doubles, and integers must
+//     lie within the range [-(2^53)+1, 2^53-1].
+//
+// Duplicate keys are reported as a *DuplicateKeyError, and other violations
+// as a *Violation. Violations halt scanning like syntax errors do, unless
+// in recovery mode, in which case each is recorded (see Errors) without an
+// Error event, and scanning continues as normal. SetStrict also resets the
+// Scanner.
+func (s *Scanner) SetStrict(on bool) {
+	s.track(func(k *keyTracker) { k.strict = on })
+}
+
+// checkString checks a string literal against the I-JSON profile.
+func (k *keyTracker) checkString() error {
+	lit := k.lit[1 : len(k.lit)-1]
+
+	for i := 0; i < len(lit); {
+		c := lit[i]
+
+		if c == '\\' {
+			if lit[i+1] != 'u' {
+				i += 2
+				continue
+			}
+
+			r := hex4(lit[i+2:])
+			n := 6
+
+			if 0xD800 <= r && r < 0xDC00 && bytes.HasPrefix(lit[i+6:], []byte(`\u`)) {
+				if r2 := hex4(lit[i+8:]); 0xDC00 <= r2 && r2 < 0xE000 {
+					r = 0x10000 + (r-0xD800)<<10 + (r2 - 0xDC00)
+					n = 12
+				}
+			}
+
+			if 0xD800 <= r && r < 0xE000 {
+				return k.violation(1+i, "lone surrogate %s in string", lit[i:i+6])
+			}
+			if isNoncharacter(r) {
+				return k.violation(1+i, "noncharacter %U in string", r)
+			}
+
+			i += n
+			continue
+		}
+
+		if c < utf8.RuneSelf {
+			i++
+			continue
+		}
+
+		r, n := utf8.DecodeRune(lit[i:])
+		if r == utf8.RuneError && n == 1 {
+			return k.violation(1+i, "invalid UTF-8 in string")
+		}
+		if isNoncharacter(r) {
+			return k.violation(1+i, "noncharacter %U in string", r)
+		}
+
+		i += n
+	}
+
+	return nil
+}
+
+// isNoncharacter reports whether r is one of Unicode's 66 noncharacters.
+func isNoncharacter(r rune) bool {
+	return 0xFDD0 <= r && r <= 0xFDEF || r&0xFFFE == 0xFFFE
+}
+
+// checkNumber checks a number literal against the I-JSON profile.
+func (k *keyTracker) checkNumber() error {
+	if bytes.IndexAny(k.lit, ".eE") < 0 {
+		n, err := strconv.ParseInt(string(k.lit), 10, 64)
+		if err != nil || n <= -1<<53 || n >= 1<<53 {
+			return k.violation(0, "integer %s outside of [-(2^53)+1, 2^53-1]", k.lit)
+		}
+		return nil
+	}
+
+	f, _ := strconv.ParseFloat(string(k.lit), 64)
+	if math.IsInf(f, 0) {
+		return k.violation(0, "number %s out of range", k.lit)
+	}
+
+	return nil
+}
+
+// violation returns a *Violation located i bytes into the current literal.
+func (k *keyTracker) violation(i int, format string, args ...interface{}) error {
+	return &Violation{
+		Pointer: k.pointer(),
+		Offset:  k.litOff + int64(i),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,106 @@
+package jo
+
+import (
+	"fmt"
+	"testing"
+)
+
+var strictTests = []struct {
+	in  string
+	err string
+}{
+	{`{"a": ["b", 1.5e300, -9007199254740991, "😀", "😀"]}`, ``},
+	{`[9007199254740991]`, ``},
+	{`[9007199254740992]`, `"/0" (offset 1): integer 9007199254740992 outside of [-(2^53)+1, 2^53-1]`},
+	{`[-9007199254740992]`, `"/0" (offset 1): integer -9007199254740992 outside of [-(2^53)+1, 2^53-1]`},
+	{`"� é \\ud800"`, ``},
+	{`{"a": 1, "a": 2}`, `duplicate key "a" at "/a" (offset 9)`},
+	{"[\"ab\xffc\"]", `"/0" (offset 4): invalid UTF-8 in string`},
+	{"{\"\xed\xa0\x80\": 1}", `"/\xed\xa0\x80" (offset 2): invalid UTF-8 in string`},
+	{`["x\ud800y"]`, `"/0" (offset 3): lone surrogate \ud800 in string`},
+	{`{"a": ["\ude00\ud800"]}`, `"/a/0" (offset 8): lone surrogate \ude00 in string`},
+	{`{"a": "\ud83dA"}`, `"/a" (offset 7): lone surrogate \ud83d in string`},
+	{`{"﷐": 1}`, `"/\ufdd0" (offset 2): noncharacter U+FDD0 in string`},
+	{`["🿿"]`, `"/0" (offset 2): noncharacter U+1FFFF in string`},
+	{"[\"\xef\xbf\xbe\"]", `"/0" (offset 2): noncharacter U+FFFE in string`},
+	{`[9007199254740993]`, `"/0" (offset 1): integer 9007199254740993 outside of [-(2^53)+1, 2^53-1]`},
+	{`{"n": -100000000000000000000}`, `"/n" (offset 6): integer -100000000000000000000 outside of [-(2^53)+1, 2^53-1]`},
+	{`1e400`, `"" (offset 0): number 1e400 out of range`},
+}
+
+func TestStrict(t *testing.T) {
+	for _, test := range strictTests {
+		var s = NewScanner()
+		var err string
+
+		s.SetStrict(true)
+
+		for _, c := range []byte(test.in) {
+			if s.Scan(c) == Error {
+				break
+			}
+		}
+		if s.End() == Error {
+			err = s.LastError().Error()
+		}
+
+		if err != test.err {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %q", err)
+			t.Errorf("  want %q", test.err)
+		}
+	}
+}
+
+func TestStrictRecoveryEvents(t *testing.T) {
+	var in = `{"\udc00": ["\ufffe", 1e999, 9007199254740993], "a": 1, "a": 2}`
+	var s, plain = NewScanner(), NewScanner()
+
+	s.SetStrict(true)
+	s.SetRecovery(true)
+
+	// The input is well-formed, so the events must be the same as outside
+	// of strict mode.
+	for i, c := range []byte(in) {
+		if got, want := s.Scan(c), plain.Scan(c); got != want {
+			t.Fatalf("offset %d: got %v, want %v", i, got, want)
+		}
+	}
+	if got, want := s.End(), plain.End(); got != want {
+		t.Fatalf("End: got %v, want %v", got, want)
+	}
+
+	if n := len(s.Errors()); n != 5 {
+		t.Errorf("got %d errors, want 5: %q", n, s.Errors())
+	}
+}
+
+func TestStrictRecovery(t *testing.T) {
+	var s = NewScanner()
+
+	s.SetStrict(true)
+	s.SetRecovery(true)
+
+	for _, c := range []byte(`{"a": "\udc00", "b": [1e999, x, 2e999], "a": null}`) {
+		s.Scan(c)
+	}
+	s.End()
+
+	var got []string
+	for _, err := range s.Errors() {
+		got = append(got, err.Error())
+	}
+
+	want := []string{
+		`"/a" (offset 7): lone surrogate \udc00 in string`,
+		`"/b/0" (offset 22): number 1e999 out of range`,
+		`invalid character 'x' in place of value start, expected '{', '[', string, number, true, false or null`,
+		`"/b/1" (offset 32): number 2e999 out of range`,
+		`duplicate key "a" at "/a" (offset 40)`,
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got  %q", got)
+		t.Errorf("want %q", want)
+	}
+}
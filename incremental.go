@@ -0,0 +1,293 @@
+package jo
+
+import (
+	"errors"
+	"sort"
+)
+
+// A Span locates a token, or a syntax error, within a buffer.
+type Span struct {
+	// One of ObjectStart, ObjectEnd, ArrayStart, ArrayEnd, KeyEnd,
+	// StringEnd, NumberEnd, BoolEnd or NullEnd, or Error.
+	Kind Event
+
+	// Offsets of the token's first byte, and of the byte following it.
+	Start, End int64
+
+	// For Error spans, the syntax error.
+	Err error
+}
+
+// An Incremental scans a buffer which is being edited, such as a document
+// open in an editor, keeping an up-to-date list of its tokens without
+// rescanning the entire buffer after each edit.
+//
+// While scanning, it records checkpoints: snapshots of the Scanner's state
+// at intervals. After an edit, scanning resumes from the last checkpoint
+// preceding it, and stops as soon as the Scanner's state matches that of
+// an old checkpoint following it, at which point the rest of the old scan
+// is known to still be valid.
+//
+// Scanning is done in recovery mode, so that syntax errors don't hide the
+// tokens following them.
+type Incremental struct {
+	buf      []byte
+	interval int64
+
+	spans  []Span
+	points []checkpoint
+}
+
+// A checkpoint records the state of scanning before a given byte.
+type checkpoint struct {
+	off int64
+	s   *Scanner
+
+	// Start of the key or scalar being scanned, or -1, and the number of
+	// spans preceding the checkpoint.
+	start int64
+	spans int
+}
+
+// NewIncremental scans a copy of buf, recording a checkpoint every interval
+// bytes. Smaller intervals mean less rescanning after edits, at the cost
+// of memory. If interval is not positive, a default of 4096 is used.
+func NewIncremental(buf []byte, interval int) *Incremental {
+	if interval <= 0 {
+		interval = 4096
+	}
+
+	x := &Incremental{
+		buf:      append([]byte{}, buf...),
+		interval: int64(interval),
+	}
+
+	s := NewScanner()
+	s.SetRecovery(true)
+
+	x.points = append(x.points, x.checkpoint(s, 0, -1))
+	x.scan(s, 0, -1, 0, nil, nil, nil)
+
+	return x
+}
+
+// Bytes returns the current contents of the buffer. The slice is only valid
+// until the next edit.
+func (x *Incremental) Bytes() []byte {
+	return x.buf
+}
+
+// Spans returns the tokens and syntax errors in the buffer, in the order
+// in which they were scanned. The slice is only valid until the next edit.
+func (x *Incremental) Spans() []Span {
+	return x.spans
+}
+
+// Edit replaces deleted bytes at offset off with the inserted ones, and
+// rescans as much of the buffer as necessary. It returns the spans which
+// have been scanned anew, and which replace any old spans in the range they
+// cover. The slice is only valid until the next edit.
+func (x *Incremental) Edit(off, deleted int, inserted []byte) ([]Span, error) {
+	if off < 0 || deleted < 0 || off+deleted > len(x.buf) {
+		return nil, errors.New("jo: edit out of range")
+	}
+
+	delta := int64(len(inserted) - deleted)
+
+	buf := make([]byte, 0, len(x.buf)+int(delta))
+	buf = append(buf, x.buf[:off]...)
+	buf = append(buf, inserted...)
+	buf = append(buf, x.buf[off+deleted:]...)
+	x.buf = buf
+
+	// Translates offsets from before the edit to offsets after it. Offsets
+	// within the deleted range have no equivalent.
+	shift := func(v int64) int64 {
+		switch {
+		case v < int64(off):
+			return v
+		case v >= int64(off+deleted):
+			return v + delta
+		}
+		return -2
+	}
+
+	// Resume scanning from the last checkpoint at or before the edit.
+	k := sort.Search(len(x.points), func(i int) bool {
+		return x.points[i].off > int64(off)
+	}) - 1
+	cp := x.points[k]
+
+	// Checkpoints following the edit are candidates for reconvergence.
+	var old []checkpoint
+	for _, p := range x.points[k+1:] {
+		if p.off >= int64(off+deleted) {
+			p.off += delta
+			if p.start >= 0 {
+				p.start = shift(p.start)
+			}
+			old = append(old, p)
+		}
+	}
+
+	prev := x.spans
+
+	// Limit the capacity of the slices, so that appending to them doesn't
+	// overwrite the old spans and checkpoints being carried over.
+	x.spans = prev[:cp.spans:cp.spans]
+	x.points = x.points[: k+1 : k+1]
+
+	s := cp.s.Clone()
+	s.off = cp.off
+
+	from, to := x.scan(s, cp.off, cp.start, int64(off), old, prev, shift)
+	return x.spans[from:to], nil
+}
+
+// scan feeds the Scanner the buffer from offset p onwards, recording spans
+// and checkpoints, until it reaches the end of the buffer or its state
+// matches one of the old checkpoints. In the latter case, the spans and
+// checkpoints following that one are carried over from prev, their offsets
+// translated by shift.
+//
+// It returns the range of spans which were scanned from offset mark and
+// onwards, up to the point of reconvergence.
+func (x *Incremental) scan(s *Scanner, p, start, mark int64, old []checkpoint, prev []Span, shift func(int64) int64) (from, to int) {
+	n := int64(len(x.buf))
+	last := p
+	from = -1
+
+	for i := p; i < n; i++ {
+		if i == mark {
+			from = len(x.spans)
+		}
+
+		for len(old) > 0 && old[0].off < i {
+			old = old[1:]
+		}
+		if len(old) > 0 && old[0].off == i && old[0].start == start && s.sameState(old[0].s) {
+			return from, x.converge(old, prev, shift)
+		}
+
+		if i-last >= x.interval {
+			x.points = append(x.points, x.checkpoint(s, i, start))
+			last = i
+		}
+
+		x.event(s, s.Scan(x.buf[i]), i, &start)
+	}
+
+	if from < 0 {
+		from = len(x.spans)
+	}
+
+	x.event(s, s.End(), n, &start)
+	return from, len(x.spans)
+}
+
+// converge carries over the spans and checkpoints following the first of
+// the old checkpoints, and returns the number of spans preceding them.
+func (x *Incremental) converge(old []checkpoint, prev []Span, shift func(int64) int64) int {
+	n := len(x.spans)
+	base := old[0].spans
+
+	for _, sp := range prev[base:] {
+		sp.End = shift(sp.End)
+
+		// The end of an object or array is signaled by the byte following
+		// it, so its bracket may lie before the checkpoint, within the
+		// edited range.
+		switch {
+		case sp.Kind == ObjectEnd, sp.Kind == ArrayEnd:
+			sp.Start = sp.End - 1
+		case sp.Start >= 0:
+			sp.Start = shift(sp.Start)
+		}
+
+		if e, ok := sp.Err.(*SyntaxError); ok {
+			moved := *e
+			moved.Offset = shift(e.Offset)
+			sp.Err = &moved
+		}
+
+		x.spans = append(x.spans, sp)
+	}
+
+	for _, p := range old {
+		p.spans += n - base
+		x.points = append(x.points, p)
+	}
+
+	return n
+}
+
+// event records the spans resulting from an event produced by the byte at
+// offset i (or the end of input, if i is the length of the buffer).
+func (x *Incremental) event(s *Scanner, ev Event, i int64, start *int64) {
+	if ev == Error {
+		end := i + 1
+		if i == int64(len(x.buf)) {
+			end = i
+		}
+		x.spans = append(x.spans, Span{Error, i, end, s.LastError()})
+		*start = -1
+		return
+	}
+
+	// Object and array ends are delayed until the following byte.
+	switch end := ev & End; end {
+	case 0:
+	case ObjectEnd, ArrayEnd:
+		x.spans = append(x.spans, Span{end, i - 1, i, nil})
+	default:
+		x.spans = append(x.spans, Span{end, *start, i, nil})
+		*start = -1
+	}
+
+	switch st := ev & Start; st {
+	case 0:
+	case ObjectStart, ArrayStart:
+		x.spans = append(x.spans, Span{st, i, i + 1, nil})
+	default:
+		*start = i
+	}
+}
+
+// checkpoint takes a snapshot of the Scanner's state before the byte at
+// offset i.
+func (x *Incremental) checkpoint(s *Scanner, i, start int64) checkpoint {
+	c := s.Clone()
+	c.errs = nil
+	return checkpoint{i, c, start, len(x.spans)}
+}
+
+// sameState reports whether two Scanners will react identically to any
+// input from here on. Their offsets, and any errors recorded so far, are
+// not taken into account.
+func (s *Scanner) sameState(t *Scanner) bool {
+	if !same(s.state, t.state) || len(s.stack) != len(t.stack) {
+		return false
+	}
+
+	// The pending end event and the recovery context are left behind once
+	// they've served their purpose, so only compare them while they matter.
+	switch st := s.state; {
+	case same(st, delayed), same(st, afterQuote), same(st, afterEsc), same(st, afterEscU),
+		same(st, afterEscU1), same(st, afterEscU12), same(st, afterEscU123):
+		if s.end != t.end {
+			return false
+		}
+	case same(st, recovering):
+		if s.sync != t.sync {
+			return false
+		}
+	}
+
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		if !same(s.stack[i], t.stack[i]) {
+			return false
+		}
+	}
+
+	return true
+}
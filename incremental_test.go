@@ -0,0 +1,185 @@
+package jo
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// describe renders spans for comparison.
+func describe(buf []byte, spans []Span) string {
+	var parts []string
+
+	for _, sp := range spans {
+		if sp.Kind == Error {
+			parts = append(parts, fmt.Sprintf("Error@%d-%d(%v)", sp.Start, sp.End, sp.Err))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s@%d-%d%q", sp.Kind, sp.Start, sp.End, buf[sp.Start:sp.End]))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+var incrementalTests = []struct {
+	in       string
+	off, del int
+	ins      string
+	out      string
+	changed  string
+}{
+	{
+		`{"a": 1, "b": [true, null]}`,
+		6, 1, `23`,
+		`{"a": 23, "b": [true, null]}`,
+		`NumberEnd@6-8"23"`,
+	},
+	{
+		`["x", "y", "z", "w", "v"]`,
+		2, 0, `"`,
+		`[""x", "y", "z", "w", "v"]`,
+		// Recovery resumes at the next comma, so the string following
+		// the error is unaffected.
+		`Error@3-4(invalid character 'x' after array element, expected ',' or ']')`,
+	},
+	{
+		`[1, 2, 3]`,
+		9, 0, ` `,
+		`[1, 2, 3] `,
+		// The end of the array is only signaled by the following byte.
+		`ArrayEnd@8-9"]"`,
+	},
+	{
+		`[1, 2, 3]`,
+		0, 9, `{}`,
+		`{}`,
+		`ObjectStart@0-1"{" ObjectEnd@1-2"}"`,
+	},
+	{
+		`[[1, 22], 3]`,
+		5, 3, `4]`,
+		`[[1, 4], 3]`,
+		// Scanning reconverges before the end of the inner array has been
+		// signaled, so the carried over span must be given a new start.
+		`NumberEnd@5-6"4"`,
+	},
+}
+
+func TestIncremental(t *testing.T) {
+	for _, test := range incrementalTests {
+		x := NewIncremental([]byte(test.in), 4)
+
+		changed, err := x.Edit(test.off, test.del, []byte(test.ins))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := string(x.Bytes()); got != test.out {
+			t.Errorf("%#q: got buffer %#q, want %#q", test.in, got, test.out)
+		}
+		if got := describe(x.Bytes(), changed); got != test.changed {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %s", got)
+			t.Errorf("  want %s", test.changed)
+		}
+
+		// The result must match a full scan.
+		want := NewIncremental(x.Bytes(), 4)
+		if got, want := describe(x.Bytes(), x.Spans()), describe(want.Bytes(), want.Spans()); got != want {
+			t.Errorf("%#q: spans after edit:", test.in)
+			t.Errorf("  got  %s", got)
+			t.Errorf("  want %s", want)
+		}
+	}
+}
+
+func TestIncrementalRandom(t *testing.T) {
+	var doc = `{"name": "jo", "tags": ["a", "b\"c", 12, -3.5e2, true, false, null],
+		"nested": {"x": [[], {}], "y": "\u00e9"}}`
+	var snippets = []string{`"`, `,`, `[`, `]`, `{`, `}`, `:`, `1`, `\`, ` `, `"k": `, `true`, `x`, "\n"}
+
+	r := rand.New(rand.NewSource(1))
+
+	for _, interval := range []int{1, 3, 16, 0} {
+		x := NewIncremental([]byte(doc), interval)
+
+		for i := 0; i < 500; i++ {
+			n := len(x.Bytes())
+			off := r.Intn(n + 1)
+			del := 0
+			if off < n && r.Intn(2) == 0 {
+				del = r.Intn(min(n-off, 4) + 1)
+			}
+			ins := ""
+			if del == 0 || r.Intn(2) == 0 {
+				ins = snippets[r.Intn(len(snippets))]
+			}
+
+			before := string(x.Bytes())
+			if _, err := x.Edit(off, del, []byte(ins)); err != nil {
+				t.Fatal(err)
+			}
+
+			want := NewIncremental(x.Bytes(), interval)
+			got, exp := describe(x.Bytes(), x.Spans()), describe(want.Bytes(), want.Spans())
+			if got != exp {
+				t.Fatalf("interval %d: Edit(%d, %d, %#q) on %#q:\n  got  %s\n  want %s",
+					interval, off, del, ins, before, got, exp)
+			}
+		}
+	}
+}
+
+func TestIncrementalBrackets(t *testing.T) {
+	var doc = `{"a": [1, 2.5, "x\"y", true, null], "b": {"c": [], "d": {}}, "e": [[0], {"f": -1e3}]}`
+	var snippets = []string{`]`, `}`, `03}`, `1]`, `"]`, `]]`, `}}`, ` ]`, `[]`, `{}`}
+
+	r := rand.New(rand.NewSource(1))
+
+	// Replace ranges ending with a closing bracket, with text ending in one,
+	// so that scanning tends to reconverge right after the bracket.
+	for _, interval := range []int{1, 2, 3, 4} {
+		for i := 0; i < 200; i++ {
+			x := NewIncremental([]byte(doc), interval)
+
+			for j := 0; j < 20; j++ {
+				buf := x.Bytes()
+
+				p := r.Intn(len(buf))
+				for p < len(buf) && buf[p] != ']' && buf[p] != '}' {
+					p++
+				}
+				if p == len(buf) {
+					break
+				}
+
+				del := 1 + r.Intn(min(p+1, 4))
+				off := p + 1 - del
+				ins := snippets[r.Intn(len(snippets))]
+
+				before := string(buf)
+				if _, err := x.Edit(off, del, []byte(ins)); err != nil {
+					t.Fatal(err)
+				}
+
+				want := NewIncremental(x.Bytes(), interval)
+				got, exp := describe(x.Bytes(), x.Spans()), describe(want.Bytes(), want.Spans())
+				if got != exp {
+					t.Fatalf("interval %d: Edit(%d, %d, %#q) on %#q:\n  got  %s\n  want %s",
+						interval, off, del, ins, before, got, exp)
+				}
+			}
+		}
+	}
+}
+
+func TestIncrementalRange(t *testing.T) {
+	x := NewIncremental([]byte(`[1]`), 0)
+
+	for _, e := range []struct{ off, del int }{{-1, 0}, {0, -1}, {2, 2}, {4, 0}} {
+		if _, err := x.Edit(e.off, e.del, nil); err == nil {
+			t.Errorf("Edit(%d, %d): expected error", e.off, e.del)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package jo
+
+import (
+	"io"
+	"iter"
+)
+
+// A Token is a complete lexical element of a document.
+type Token struct {
+	// One of ObjectStart, ObjectEnd, ArrayStart, ArrayEnd, KeyEnd,
+	// StringEnd, NumberEnd, BoolEnd or NullEnd.
+	Kind Event
+
+	// Raw text of keys and scalar values, exactly as it appears in the
+	// input. Only valid until the next token is requested.
+	Raw []byte
+
+	// Offset of the token's first byte.
+	Offset int64
+
+	// For KeyEnd tokens, whether the key repeats an earlier key in the
+	// same object. See Decoder.SetDuplicatePolicy.
+	Duplicate bool
+}
+
+// A Decoder reads a stream of tokens from an io.Reader.
+type Decoder struct {
+	t *tokenizer
+}
+
+// NewDecoder returns a Decoder reading a single document from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{newTokenizer(r)}
+}
+
+// SetDuplicatePolicy configures how duplicate object keys are treated. It
+// must be called before any tokens are read.
+func (d *Decoder) SetDuplicatePolicy(p DuplicatePolicy) {
+	d.t.s.SetDuplicatePolicy(p)
+}
+
+// Tokens returns an iterator over the remaining tokens in the document. If
+// a syntax or I/O error occurs, it is yielded along with a zero Token as
+// the final element.
+//
+// Breaking out of a loop leaves the Decoder positioned right after the
+// last token yielded, so that a subsequent call to Tokens picks up where
+// the previous one left off.
+func (d *Decoder) Tokens() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		for {
+			tok, err := d.t.next()
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(Token{}, err)
+				return
+			}
+
+			if !yield(Token{tok.kind, tok.text, tok.off, tok.dup}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Pointer returns a JSON Pointer identifying the location of the most
+// recently yielded token.
+func (d *Decoder) Pointer() string {
+	return d.t.pointer(false)
+}
+
+// Tokens returns an iterator over the tokens of a single document read
+// from r. It is shorthand for NewDecoder(r).Tokens().
+func Tokens(r io.Reader) iter.Seq2[Token, error] {
+	return NewDecoder(r).Tokens()
+}
+
+// A Value is a raw, encoded JSON value.
+type Value []byte
+
+// Kind returns the start event of the value's first token: ObjectStart,
+// ArrayStart, StringStart, NumberStart, BoolStart or NullStart. It returns
+// Error if the value doesn't begin with a valid token.
+func (v Value) Kind() Event {
+	var s = NewScanner()
+
+	for _, c := range v {
+		if ev := s.Scan(c); ev != Space {
+			if ev != Error {
+				return ev & Start
+			}
+			break
+		}
+	}
+
+	return Error
+}
+
+// Members returns an iterator over the members of an object, yielding each
+// member's unescaped key and raw value. Values are scanned lazily, one
+// member at a time; iteration ends early if a syntax error is found, or
+// immediately if v is not an object.
+func (v Value) Members() iter.Seq2[string, Value] {
+	return func(yield func(string, Value) bool) {
+		each(v, ObjectStart, func(key []byte, _, start, end int) bool {
+			return yield(string(unquote(nil, key)), v[start:end])
+		})
+	}
+}
+
+// Elements returns an iterator over the elements of an array, yielding each
+// element's index and raw value. Values are scanned lazily, one element at
+// a time; iteration ends early if a syntax error is found, or immediately
+// if v is not an array.
+func (v Value) Elements() iter.Seq2[int, Value] {
+	return func(yield func(int, Value) bool) {
+		var i int
+		each(v, ArrayStart, func(_ []byte, _, start, end int) bool {
+			i++
+			return yield(i-1, v[start:end])
+		})
+	}
+}
+
+// each scans v, which should be an object or array of the specified kind
+// (or of either, if kind is zero), and invokes fn with the raw key of each
+// child (nil for array elements), the offset at which the child begins (at
+// its key, for object members), and the boundaries of its raw value, until
+// fn returns false or a syntax error is found. It returns the kind of v's
+// first token.
+func each(v []byte, kind Event, fn func(key []byte, from, start, end int) bool) Event {
+	var s = NewScanner()
+	var first Event
+	var depth, from, key, start int
+
+	for i := 0; i <= len(v); i++ {
+		var ev Event
+
+		if i < len(v) {
+			ev = s.Scan(v[i])
+		} else {
+			ev = s.End()
+		}
+
+		if ev == Error {
+			return first
+		}
+
+		if ev&End != 0 {
+			if ev&(ObjectEnd|ArrayEnd) != 0 {
+				depth--
+			}
+			if depth == 1 {
+				if ev&KeyEnd != 0 {
+					key = i
+				} else if first == ObjectStart {
+					if !fn(v[from:key], from, start, i) {
+						return first
+					}
+				} else if !fn(nil, start, start, i) {
+					return first
+				}
+			}
+		}
+
+		if ev&Start != 0 {
+			if depth == 0 {
+				if first = ev & Start; kind != 0 && first != kind {
+					return first
+				}
+			}
+			if depth == 1 {
+				if ev&KeyStart != 0 {
+					from = i
+				} else {
+					start = i
+				}
+			}
+			if ev&(ObjectStart|ArrayStart) != 0 {
+				depth++
+			}
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,125 @@
+package jo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	var d = NewDecoder(strings.NewReader(`{"a": [1, true], "b": null}`))
+	var out []string
+
+	for tok, err := range d.Tokens() {
+		if err != nil {
+			t.Fatalf("Decoder.Tokens yielded %q", err)
+		}
+		out = append(out, fmt.Sprintf("%d %s %s %q", tok.Offset, tok.Kind, d.Pointer(), tok.Raw))
+		if tok.Kind == NumberEnd {
+			break
+		}
+	}
+
+	// Resume iterating after breaking out of the previous loop.
+	for tok, err := range d.Tokens() {
+		if err != nil {
+			t.Fatalf("Decoder.Tokens yielded %q", err)
+		}
+		out = append(out, fmt.Sprintf("%d %s %s %q", tok.Offset, tok.Kind, d.Pointer(), tok.Raw))
+	}
+
+	want := []string{
+		`0 ObjectStart  ""`,
+		`1 KeyEnd /a "\"a\""`,
+		`6 ArrayStart /a ""`,
+		`7 NumberEnd /a/0 "1"`,
+		`10 BoolEnd /a/1 "true"`,
+		`14 ArrayEnd /a ""`,
+		`17 KeyEnd /b "\"b\""`,
+		`22 NullEnd /b "null"`,
+		`26 ObjectEnd  ""`,
+	}
+
+	if strings.Join(out, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Decoder.Tokens:")
+		t.Errorf("  got  %q", out)
+		t.Errorf("  want %q", want)
+	}
+}
+
+func TestTokensError(t *testing.T) {
+	var n int
+
+	for tok, err := range Tokens(strings.NewReader(`[1 2]`)) {
+		if n++; n == 3 {
+			if err == nil || tok.Kind != None {
+				t.Errorf("Tokens did not yield syntax error")
+			}
+		}
+	}
+
+	if n != 3 {
+		t.Errorf("Tokens yielded %d elements, want 3", n)
+	}
+}
+
+func TestValueMembers(t *testing.T) {
+	var v = Value(` { "a" : 1 , "b!": {"c": [2, {}]}, "d":"x", "e": true } `)
+	var out []string
+
+	for key, val := range v.Members() {
+		out = append(out, fmt.Sprintf("%s=%s", key, val))
+		if key == "d" {
+			break
+		}
+	}
+
+	want := `a=1 b!={"c": [2, {}]} d="x"`
+	if strings.Join(out, " ") != want {
+		t.Errorf("Value.Members:")
+		t.Errorf("  got  %s", strings.Join(out, " "))
+		t.Errorf("  want %s", want)
+	}
+
+	for range Value(`[1]`).Members() {
+		t.Errorf("Value.Members yielded members of an array")
+	}
+}
+
+func TestValueElements(t *testing.T) {
+	var v = Value(`[1, "two", [3], {"four": 4}, null, x]`)
+	var out []string
+
+	for i, el := range v.Elements() {
+		out = append(out, fmt.Sprintf("%d=%s", i, el))
+	}
+
+	want := `0=1 1="two" 2=[3] 3={"four": 4} 4=null`
+	if strings.Join(out, " ") != want {
+		t.Errorf("Value.Elements:")
+		t.Errorf("  got  %s", strings.Join(out, " "))
+		t.Errorf("  want %s", want)
+	}
+}
+
+var valueKindTests = []struct {
+	in  string
+	out Event
+}{
+	{` {}`, ObjectStart},
+	{`[`, ArrayStart},
+	{`"x"`, StringStart},
+	{`-1`, NumberStart},
+	{`false`, BoolStart},
+	{`null`, NullStart},
+	{``, Error},
+	{`x`, Error},
+}
+
+func TestValueKind(t *testing.T) {
+	for _, test := range valueKindTests {
+		if out := Value(test.in).Kind(); out != test.out {
+			t.Errorf("Value(%#q).Kind() = %s, want %s", test.in, out, test.out)
+		}
+	}
+}
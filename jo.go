@@ -125,8 +125,35 @@ type Scanner struct {
 	// Used when delaying end events.
 	end Event
 
-	// Persisted syntax error.
-	err error
+	// Number of bytes scanned, and whether End is being called.
+	off    int64
+	ending bool
+
+	// Persisted syntax error, and all errors encountered since the last
+	// reset (more than one only in recovery mode).
+	err  error
+	errs []error
+
+	// Whether to accept newline-separated values (JSON Lines), and whether
+	// to resume scanning after syntax errors.
+	lines   bool
+	recover bool
+
+	// State function of a number literal ended by the byte being scanned
+	// (or by the end of input), as that byte could also have continued it.
+	number func(*Scanner, byte) Event
+
+	// State function in which the last error occurred, and the context in
+	// which to resume scanning.
+	failed func(*Scanner, byte) Event
+	sync   int
+
+	// Keeps track of object keys when detecting duplicates, and checks
+	// literals in strict mode.
+	keys *keyTracker
+
+	// Enforces resource limits, if any.
+	limits *limiter
 }
 
 // NewScanner initializes a new Scanner.
@@ -138,43 +165,160 @@ func NewScanner() *Scanner {
 
 // Reset restores a Scanner to its initial state.
 func (s *Scanner) Reset() {
-	s.state = beforeValue
-	s.stack = append(s.stack[:0], afterTopValue)
+	if s.lines {
+		s.state = betweenLines
+		s.stack = s.stack[:0]
+	} else {
+		s.state = beforeValue
+		s.stack = append(s.stack[:0], afterTopValue)
+	}
+
+	s.off = 0
 	s.err = nil
+	s.errs = nil
+
+	if s.keys != nil {
+		s.keys.reset()
+	}
+	if s.limits != nil {
+		s.limits.reset()
+	}
+}
+
+// Clone returns a copy of the Scanner, in the same state but independent of
+// it, so that it can be fed speculative input. Unless duplicate keys are
+// being tracked, which requires copying the keys seen in all open objects,
+// the cost is proportional to the nesting depth.
+func (s *Scanner) Clone() *Scanner {
+	c := *s
+	c.stack = append(make([]func(*Scanner, byte) Event, 0, cap(s.stack)), s.stack...)
+	c.errs = s.errs[:len(s.errs):len(s.errs)]
+
+	if s.keys != nil {
+		c.keys = s.keys.clone()
+	}
+	if s.limits != nil {
+		c.limits = s.limits.clone()
+	}
+
+	return &c
+}
+
+// SetLines configures the Scanner to accept a sequence of values separated
+// by newlines, as in the JSON Lines format, rather than a single value. It
+// also resets the Scanner.
+func (s *Scanner) SetLines(on bool) {
+	s.lines = on
+	s.Reset()
+}
+
+// SetRecovery enables or disables recovery mode. In recovery mode the
+// Scanner records each syntax error, skips ahead to the next ',', '}' or
+// ']' and resumes scanning from there. In JSON Lines mode it instead skips
+// to the next newline, abandoning the rest of the broken line.
+//
+// Any value being scanned when an error occurs is abandoned without an end
+// event, as are any unterminated objects and arrays when a line is skipped.
+func (s *Scanner) SetRecovery(on bool) {
+	s.recover = on
 }
 
 // Scan accepts a byte of input and returns an Event.
 func (s *Scanner) Scan(c byte) Event {
-	return s.state(s, c)
+	ev := s.state(s, c)
+	if ev == Error {
+		if _, ok := s.err.(*LimitError); s.recover && !ok {
+			s.resync(c)
+		}
+	} else {
+		if s.limits != nil {
+			ev = s.limit(c, ev)
+		}
+		if s.keys != nil && ev != Error {
+			ev = s.observe(c, ev)
+		}
+	}
+	s.off++
+	return ev
 }
 
 // End signals the Scanner that the end of input has been reached. It returns
 // an event just as Scan does.
 func (s *Scanner) End() Event {
 	// Feeding the state function whitespace may trigger NumberEnd events.
-	// Note the mask operation to filter out the actual Space bit.
-	ev := s.state(s, ' ') & (^Space)
+	// Note the mask operation below, filtering out the actual Space bit.
+	s.ending = true
+	ev := s.state(s, ' ')
 
-	if s.err != nil {
+	if ev != Error && s.limits != nil {
+		ev = s.limit(' ', ev)
+	}
+	if ev != Error && s.keys != nil {
+		ev = s.observe(' ', ev)
+	}
+
+	s.ending = false
+
+	if ev == Error || s.err != nil && !s.recover {
+		s.number = nil
 		return Error
 	}
+
+	ev &= ^Space
 	if len(s.stack) > 0 {
-		return s.errorf(`unexpected end of JSON input`)
+		ev = s.fail(-1, `unexpected end of JSON input`)
 	}
 
+	s.number = nil
 	return ev
 }
 
+// Offset returns the number of bytes scanned since the Scanner was last
+// reset.
+func (s *Scanner) Offset() int64 {
+	return s.off
+}
+
 // LastError returns a syntax error description after either Scan or End has
-// returned an Error event.
+// returned an Error event. The error is a *SyntaxError, a *DuplicateKeyError
+// if duplicate keys are being rejected, a *Violation in strict mode, or a
+// *LimitError. In recovery mode, duplicate keys and strict mode violations
+// are recorded without an Error event.
 func (s *Scanner) LastError() error {
 	return s.err
 }
 
-// errorf generates and persists an error.
-func (s *Scanner) errorf(str string, args ...interface{}) Event {
+// Errors returns all syntax errors encountered since the Scanner was last
+// reset. Outside of recovery mode there is at most one.
+func (s *Scanner) Errors() []error {
+	return s.errs
+}
+
+// invalid generates and persists an error for an unexpected byte of input,
+// or for the end of input if End is being called.
+func (s *Scanner) invalid(c byte, context string) Event {
+	if s.ending {
+		return s.fail(-1, `unexpected end of JSON input`)
+	}
+	return s.fail(int(c), fmt.Sprintf(`invalid character %q %s`, c, context))
+}
+
+// fail generates and persists a syntax error.
+func (s *Scanner) fail(c int, msg string) Event {
+	return s.raise(&SyntaxError{
+		Offset:   s.off,
+		Char:     c,
+		Expected: s.expected(),
+		msg:      msg,
+	})
+}
+
+// raise persists an error and halts scanning.
+func (s *Scanner) raise(err error) Event {
+	s.failed = s.state
 	s.state = afterError
-	s.err = fmt.Errorf(str, args...)
+	s.err = err
+	s.errs = append(s.errs, err)
 	return Error
 }
 
@@ -192,6 +336,16 @@ func (s *Scanner) next(c byte) Event {
 	return s.state(s, c)
 }
 
+// endNumber ends a number literal, passing c on to the next state.
+func (s *Scanner) endNumber(c byte) Event {
+	s.number = s.state
+	ev := s.next(c)
+	if !s.ending {
+		s.number = nil
+	}
+	return ev | NumberEnd
+}
+
 // delay schedules an end event to be returned for the next byte of input.
 func (s *Scanner) delay(ev Event) Event {
 	s.state = delayed
@@ -234,7 +388,7 @@ func beforeValue(s *Scanner, c byte) Event {
 		return NullStart
 	}
 
-	return s.errorf(`invalid character %q in place of value start`)
+	return s.invalid(c, `in place of value start`)
 }
 
 func beforeFirstObjectKey(s *Scanner, c byte) Event {
@@ -249,7 +403,7 @@ func beforeFirstObjectKey(s *Scanner, c byte) Event {
 		return s.delay(ObjectEnd)
 	}
 
-	return s.errorf(`invalid character %q in object`, c)
+	return s.invalid(c, `in object`)
 }
 
 func afterObjectKey(s *Scanner, c byte) Event {
@@ -261,7 +415,7 @@ func afterObjectKey(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after object key`, c)
+	return s.invalid(c, `after object key`)
 }
 
 func afterObjectValue(s *Scanner, c byte) Event {
@@ -274,7 +428,7 @@ func afterObjectValue(s *Scanner, c byte) Event {
 		return s.delay(ObjectEnd)
 	}
 
-	return s.errorf(`invalid character %q after object value`, c)
+	return s.invalid(c, `after object value`)
 }
 
 func afterObjectComma(s *Scanner, c byte) Event {
@@ -287,7 +441,7 @@ func afterObjectComma(s *Scanner, c byte) Event {
 		return KeyStart
 	}
 
-	return s.errorf(`invalid character %q in place of object key`, c)
+	return s.invalid(c, `in place of object key`)
 }
 
 func beforeFirstArrayElement(s *Scanner, c byte) Event {
@@ -312,7 +466,7 @@ func afterArrayElement(s *Scanner, c byte) Event {
 		return s.delay(ArrayEnd)
 	}
 
-	return s.errorf(`invalid character %q after array element`, c)
+	return s.invalid(c, `after array element`)
 }
 
 func afterQuote(s *Scanner, c byte) Event {
@@ -328,7 +482,7 @@ func afterQuote(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in string literal`, c)
+	return s.invalid(c, `in string literal`)
 }
 
 func afterEsc(s *Scanner, c byte) Event {
@@ -340,7 +494,7 @@ func afterEsc(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in character escape`, c)
+	return s.invalid(c, `in character escape`)
 }
 
 func afterEscU(s *Scanner, c byte) Event {
@@ -349,7 +503,7 @@ func afterEscU(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.invalid(c, `in hexadecimal character escape`)
 }
 
 func afterEscU1(s *Scanner, c byte) Event {
@@ -358,7 +512,7 @@ func afterEscU1(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.invalid(c, `in hexadecimal character escape`)
 }
 
 func afterEscU12(s *Scanner, c byte) Event {
@@ -367,7 +521,7 @@ func afterEscU12(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.invalid(c, `in hexadecimal character escape`)
 }
 
 func afterEscU123(s *Scanner, c byte) Event {
@@ -376,7 +530,7 @@ func afterEscU123(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in hexadecimal character escape`, c)
+	return s.invalid(c, `in hexadecimal character escape`)
 }
 
 func afterMinus(s *Scanner, c byte) Event {
@@ -388,7 +542,7 @@ func afterMinus(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "-"`, c)
+	return s.invalid(c, `after "-"`)
 }
 
 func afterZero(s *Scanner, c byte) Event {
@@ -400,7 +554,7 @@ func afterZero(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.next(c) | NumberEnd
+	return s.endNumber(c)
 }
 
 func afterDigit(s *Scanner, c byte) Event {
@@ -417,7 +571,7 @@ func afterDot(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after decimal point in numeric literal`, c)
+	return s.invalid(c, `after decimal point in numeric literal`)
 }
 
 func afterDotDigit(s *Scanner, c byte) Event {
@@ -428,7 +582,7 @@ func afterDotDigit(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.next(c) | NumberEnd
+	return s.endNumber(c)
 }
 
 func afterE(s *Scanner, c byte) Event {
@@ -440,7 +594,7 @@ func afterE(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in exponent of numeric literal`, c)
+	return s.invalid(c, `in exponent of numeric literal`)
 }
 
 func afterESign(s *Scanner, c byte) Event {
@@ -449,7 +603,7 @@ func afterESign(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q in exponent of numeric literal`, c)
+	return s.invalid(c, `in exponent of numeric literal`)
 }
 
 func afterEDigit(s *Scanner, c byte) Event {
@@ -457,7 +611,7 @@ func afterEDigit(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.next(c) | NumberEnd
+	return s.endNumber(c)
 }
 
 func afterT(s *Scanner, c byte) Event {
@@ -466,7 +620,7 @@ func afterT(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "t"`, c)
+	return s.invalid(c, `after "t"`)
 }
 
 func afterTr(s *Scanner, c byte) Event {
@@ -475,7 +629,7 @@ func afterTr(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "tr"`, c)
+	return s.invalid(c, `after "tr"`)
 }
 
 func afterTru(s *Scanner, c byte) Event {
@@ -483,7 +637,7 @@ func afterTru(s *Scanner, c byte) Event {
 		return s.delay(BoolEnd)
 	}
 
-	return s.errorf(`invalid character %q after "tru"`, c)
+	return s.invalid(c, `after "tru"`)
 }
 
 func afterF(s *Scanner, c byte) Event {
@@ -492,7 +646,7 @@ func afterF(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "f"`, c)
+	return s.invalid(c, `after "f"`)
 }
 
 func afterFa(s *Scanner, c byte) Event {
@@ -501,7 +655,7 @@ func afterFa(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "fa"`, c)
+	return s.invalid(c, `after "fa"`)
 }
 
 func afterFal(s *Scanner, c byte) Event {
@@ -510,7 +664,7 @@ func afterFal(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "fal"`, c)
+	return s.invalid(c, `after "fal"`)
 }
 
 func afterFals(s *Scanner, c byte) Event {
@@ -518,7 +672,7 @@ func afterFals(s *Scanner, c byte) Event {
 		return s.delay(BoolEnd)
 	}
 
-	return s.errorf(`invalid character %q after "fals"`, c)
+	return s.invalid(c, `after "fals"`)
 }
 
 func afterN(s *Scanner, c byte) Event {
@@ -527,7 +681,7 @@ func afterN(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "n"`, c)
+	return s.invalid(c, `after "n"`)
 }
 
 func afterNu(s *Scanner, c byte) Event {
@@ -536,7 +690,7 @@ func afterNu(s *Scanner, c byte) Event {
 		return None
 	}
 
-	return s.errorf(`invalid character %q after "nu"`, c)
+	return s.invalid(c, `after "nu"`)
 }
 
 func afterNul(s *Scanner, c byte) Event {
@@ -544,19 +698,35 @@ func afterNul(s *Scanner, c byte) Event {
 		return s.delay(NullEnd)
 	}
 
-	return s.errorf(`invalid character %q after "nul"`, c)
+	return s.invalid(c, `after "nul"`)
 }
 
 func delayed(s *Scanner, c byte) Event {
-	return s.next(c) | s.end
+	// Read s.end before invoking the next state function, which may well
+	// schedule a delayed event of its own.
+	end := s.end
+	return s.next(c) | end
 }
 
 func afterTopValue(s *Scanner, c byte) Event {
+	if table[c]&isSpace != 0 {
+		if c == '\n' && s.lines {
+			s.state = betweenLines
+		}
+		return Space
+	}
+
+	return s.invalid(c, `after top-level value`)
+}
+
+func betweenLines(s *Scanner, c byte) Event {
 	if table[c]&isSpace != 0 {
 		return Space
 	}
 
-	return s.errorf(`invalid character %q after top-level value`, c)
+	s.state = beforeValue
+	s.push(afterTopValue)
+	return beforeValue(s, c)
 }
 
 func afterError(s *Scanner, c byte) Event {
@@ -168,6 +168,60 @@ var scannerTests = []struct {
 			ArrayEnd,          // EOF
 		},
 	},
+	{
+		`["a",true]`,
+		[]Event{
+			ArrayStart,  // '['
+			StringStart, // '"'
+			None,        // 'a'
+			None,        // '"'
+			StringEnd,   // ','
+			BoolStart,   // 't'
+			None,        // 'r'
+			None,        // 'u'
+			None,        // 'e'
+			BoolEnd,     // ']'
+			ArrayEnd,    // EOF
+		},
+	},
+	{
+		`{"a":"b"}`,
+		[]Event{
+			ObjectStart, // '{'
+			KeyStart,    // '"'
+			None,        // 'a'
+			None,        // '"'
+			KeyEnd,      // ':'
+			StringStart, // '"'
+			None,        // 'b'
+			None,        // '"'
+			StringEnd,   // '}'
+			ObjectEnd,   // EOF
+		},
+	},
+	{
+		`[["a"],{"b":"c"}]`,
+		[]Event{
+			ArrayStart,  // '['
+			ArrayStart,  // '['
+			StringStart, // '"'
+			None,        // 'a'
+			None,        // '"'
+			StringEnd,   // ']'
+			ArrayEnd,    // ','
+			ObjectStart, // '{'
+			KeyStart,    // '"'
+			None,        // 'b'
+			None,        // '"'
+			KeyEnd,      // ':'
+			StringStart, // '"'
+			None,        // 'c'
+			None,        // '"'
+			StringEnd,   // '}'
+			ObjectEnd,   // ']'
+			ArrayEnd,    // EOF
+		},
+	},
 	{
 		`"foo"`,
 		[]Event{
@@ -674,3 +728,66 @@ func TestScannerErrors(t *testing.T) {
 		t.Fatalf("Scanner.End did not remember previous error")
 	}
 }
+
+var linesTests = []struct {
+	in  string
+	out []Event
+}{
+	{
+		"1\n\n[]\n",
+		[]Event{
+			NumberStart,       // '1'
+			NumberEnd | Space, // '\n'
+			Space,             // '\n'
+			ArrayStart,        // '['
+			None,              // ']'
+			ArrayEnd | Space,  // '\n'
+			None,              // EOF
+		},
+	},
+	{
+		"",
+		[]Event{
+			None, // EOF
+		},
+	},
+	{
+		"1 2",
+		[]Event{
+			NumberStart,       // '1'
+			NumberEnd | Space, // ' '
+			Error,             // '2'
+		},
+	},
+	{
+		"[\n",
+		[]Event{
+			ArrayStart, // '['
+			Space,      // '\n'
+			Error,      // EOF
+		},
+	},
+}
+
+func TestScannerLines(t *testing.T) {
+	for _, test := range linesTests {
+		var s = NewScanner()
+		var out []Event
+
+		s.SetLines(true)
+
+		for i := range test.out {
+			if i < len(test.in) {
+				out = append(out, s.Scan(test.in[i]))
+			} else {
+				out = append(out, s.End())
+			}
+		}
+
+		if fmt.Sprint(out) != fmt.Sprint(test.out) {
+			t.Errorf("Scanner(%#q) in JSON Lines mode:", test.in)
+			t.Errorf("  got  %s", out)
+			t.Errorf("  want %s", test.out)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package jo
+
+import (
+	"fmt"
+)
+
+// Limits bounds the resources a document may consume. Zero fields impose
+// no limit.
+type Limits struct {
+	// Total number of bytes scanned since the last reset, including
+	// whitespace and, in JSON Lines mode, all previous documents.
+	Bytes int64
+
+	// Nesting depth of objects and arrays.
+	Depth int
+
+	// Length of a string or key, and of a number literal, in bytes as
+	// encoded. Quotes are not counted.
+	String int
+	Number int
+
+	// Number of members in an object, and of elements in an array.
+	Members  int
+	Elements int
+}
+
+// A LimitError is returned when a document exceeds one of the Scanner's
+// limits.
+type LimitError struct {
+	// Name of the limit, such as "string length".
+	Limit string
+
+	// The limit's value, and the offset of the byte which exceeded it.
+	Max    int64
+	Offset int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded at offset %d", e.Limit, e.Max, e.Offset)
+}
+
+// SetLimits configures the Scanner's resource limits. A document exceeding
+// any of them is rejected as soon as the offending byte is scanned, and
+// scanning halts even in recovery mode. SetLimits also resets the Scanner.
+func (s *Scanner) SetLimits(l Limits) {
+	if l == (Limits{}) {
+		s.limits = nil
+	} else {
+		s.limits = &limiter{Limits: l}
+	}
+	s.Reset()
+}
+
+// A limiter keeps count of the resources consumed by a document.
+type limiter struct {
+	Limits
+
+	// Number of members or elements in each open object or array.
+	counts []count
+
+	// Kind of literal being scanned, if any, and its length so far.
+	lit Event
+	n   int
+}
+
+type count struct {
+	array bool
+	n     int
+}
+
+func (l *limiter) reset() {
+	l.counts = l.counts[:0]
+	l.lit = None
+}
+
+func (l *limiter) clone() *limiter {
+	c := *l
+	c.counts = append([]count{}, l.counts...)
+	return &c
+}
+
+// limit updates the limiter with another event, returning Error if a limit
+// has been exceeded.
+func (s *Scanner) limit(c byte, ev Event) Event {
+	l := s.limits
+
+	if l.Bytes > 0 && s.off >= l.Bytes && !s.ending {
+		return s.exceed("document size", l.Bytes)
+	}
+
+	// Objects and arrays may be out of step with the input after recovering
+	// from a syntax error, hence the bounds checks.
+	n := len(l.counts)
+
+	switch end := ev & End; end {
+	case ObjectEnd, ArrayEnd:
+		if n > 0 {
+			l.counts = l.counts[:n-1]
+		}
+	case KeyEnd, StringEnd, NumberEnd, BoolEnd, NullEnd:
+		l.lit = None
+	}
+
+	if start := ev & Start; start != 0 {
+		if n := len(l.counts); n > 0 && start != KeyStart {
+			if c := &l.counts[n-1]; c.array {
+				if c.n++; l.Elements > 0 && c.n > l.Elements {
+					return s.exceed("array element", int64(l.Elements))
+				}
+			}
+		}
+
+		switch start {
+		case ObjectStart, ArrayStart:
+			l.counts = append(l.counts, count{array: start == ArrayStart})
+			if l.Depth > 0 && len(l.counts) > l.Depth {
+				return s.exceed("depth", int64(l.Depth))
+			}
+		case KeyStart:
+			if n > 0 {
+				if c := &l.counts[n-1]; !c.array {
+					if c.n++; l.Members > 0 && c.n > l.Members {
+						return s.exceed("object member", int64(l.Members))
+					}
+				}
+			}
+			fallthrough
+		case StringStart:
+			l.lit, l.n = start, 0
+		case NumberStart:
+			l.lit, l.n = start, 1
+			if l.Number > 0 && l.n > l.Number {
+				return s.exceed("number length", int64(l.Number))
+			}
+		}
+	} else if l.lit == KeyStart || l.lit == StringStart {
+		// An unescaped quote ends the string, leaving the Scanner in a
+		// state other than afterQuote.
+		if c == '"' && !same(s.state, afterQuote) {
+			l.lit = None
+		} else if l.n++; l.String > 0 && l.n > l.String {
+			return s.exceed("string length", int64(l.String))
+		}
+	} else if l.lit == NumberStart {
+		if l.n++; l.Number > 0 && l.n > l.Number {
+			return s.exceed("number length", int64(l.Number))
+		}
+	}
+
+	return ev
+}
+
+// exceed reports an exceeded limit, and halts scanning for good.
+func (s *Scanner) exceed(name string, max int64) Event {
+	return s.raise(&LimitError{name, max, s.off})
+}
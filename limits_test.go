@@ -0,0 +1,74 @@
+package jo
+
+import (
+	"testing"
+)
+
+var limitTests = []struct {
+	limits Limits
+	in     string
+	err    string
+}{
+	{Limits{Bytes: 8}, `[1, 2] `, ``},
+	{Limits{Bytes: 8}, `[1, 2]  `, ``},
+	{Limits{Bytes: 8}, `[1, 2]   `, `document size limit of 8 exceeded at offset 8`},
+	{Limits{Depth: 2}, `[{"a": 1}, [[]]]`, `depth limit of 2 exceeded at offset 12`},
+	{Limits{Depth: 2}, `{"a": [{}]}`, `depth limit of 2 exceeded at offset 7`},
+	{Limits{String: 3}, `["abc", {"xyz": "\n"}, "\""]`, ``},
+	{Limits{String: 3}, `["abcd"]`, `string length limit of 3 exceeded at offset 5`},
+	{Limits{String: 3}, `["ab\n"]`, `string length limit of 3 exceeded at offset 5`},
+	{Limits{String: 3}, `"abcd"`, `string length limit of 3 exceeded at offset 4`},
+	{Limits{String: 3}, `"abc"`, ``},
+	{Limits{String: 3}, `{"abcdef": 1}`, `string length limit of 3 exceeded at offset 5`},
+	{Limits{Number: 4}, `[1234, -1.5, 1e10]`, ``},
+	{Limits{Number: 4}, `[12345]`, `number length limit of 4 exceeded at offset 5`},
+	{Limits{Number: 4}, `12345`, `number length limit of 4 exceeded at offset 4`},
+	{Limits{Members: 2}, `{"a": {"b": 1, "c": 2}, "d": []}`, ``},
+	{Limits{Members: 2}, `{"a": 1, "b": 2, "c": 3}`, `object member limit of 2 exceeded at offset 17`},
+	{Limits{Elements: 2}, `[[1, 2], [3, 4]]`, ``},
+	{Limits{Elements: 2}, `[[1, 2, 3]]`, `array element limit of 2 exceeded at offset 8`},
+	{Limits{Elements: 2}, `[{}, [], "x"]`, `array element limit of 2 exceeded at offset 9`},
+}
+
+func TestLimits(t *testing.T) {
+	for _, test := range limitTests {
+		var s = NewScanner()
+		var err string
+
+		s.SetLimits(test.limits)
+
+		for _, c := range []byte(test.in) {
+			if s.Scan(c) == Error {
+				break
+			}
+		}
+		if s.End() == Error {
+			err = s.LastError().Error()
+		}
+
+		if err != test.err {
+			t.Errorf("%+v %#q:", test.limits, test.in)
+			t.Errorf("  got  %q", err)
+			t.Errorf("  want %q", test.err)
+		}
+	}
+}
+
+func TestLimitsRecovery(t *testing.T) {
+	var s = NewScanner()
+
+	s.SetLimits(Limits{Elements: 1})
+	s.SetRecovery(true)
+
+	for _, c := range []byte(`[x, 1, 2, 3]`) {
+		s.Scan(c)
+	}
+	s.End()
+
+	if errs := s.Errors(); len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2", len(errs))
+	}
+	if _, ok := s.LastError().(*LimitError); !ok {
+		t.Errorf("got %T, want *LimitError", s.LastError())
+	}
+}
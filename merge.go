@@ -0,0 +1,211 @@
+package jo
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to doc and returns the
+// result. Like ApplyPatch, it splices raw bytes, preserving the parts of doc
+// which aren't touched by the patch.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	if _, err := decodeBytes(doc); err != nil {
+		return nil, err
+	}
+	if _, err := decodeBytes(patch); err != nil {
+		return nil, err
+	}
+
+	start, end, _ := locate(doc, nil)
+	return splice(doc, start, end, mergeValue(doc[start:end], trim(patch))), nil
+}
+
+// mergeValue applies a merge patch to target. A nil target stands for a
+// missing value.
+func mergeValue(target, patch []byte) []byte {
+	if patch[0] != '{' {
+		return patch
+	}
+	if len(target) == 0 || target[0] != '{' {
+		target = []byte("{}")
+	}
+
+	_, ps := children(patch)
+
+	for _, p := range ps {
+		_, ts := children(target)
+		i := find(ts, p.key)
+
+		switch {
+		case isNull(p.value):
+			if i >= 0 {
+				target = without(target, ts, i)
+			}
+		case i >= 0:
+			target = splice(target, ts[i].start, ts[i].end, mergeValue(ts[i].value, p.value))
+		default:
+			target = withMember(target, ts, p.key, mergeValue(nil, p.value))
+		}
+	}
+
+	return target
+}
+
+func isNull(v []byte) bool {
+	return string(v) == "null"
+}
+
+// Diff compares two documents and describes their differences as both a
+// JSON Merge Patch (RFC 7386) and a JSON Patch (RFC 6902), either of which
+// transforms a into b. Values are copied from b in their original form.
+//
+// The documents are not scanned in a single lockstep pass. Instead, values
+// which differ byte for byte are split into their members or elements one
+// level at a time, and those found on both sides are compared recursively.
+// This matches object members by key regardless of their order, which a
+// lockstep walk could not do. Arrays are compared element by element, with
+// elements added or removed at the end; the merge patch replaces any array
+// which differs in its entirety, as RFC 7386 has no other way of modifying
+// arrays.
+//
+// Note that a merge patch can't set a member's value to null, since null
+// signifies removal. Such changes are only accurately described by the
+// JSON Patch.
+func Diff(a, b []byte) (merge, patch []byte, err error) {
+	if _, err := decodeBytes(a); err != nil {
+		return nil, nil, err
+	}
+	if _, err := decodeBytes(b); err != nil {
+		return nil, nil, err
+	}
+
+	d := &differ{patch: []byte{'['}}
+
+	merge = d.diff(nil, trim(a), trim(b))
+	if merge == nil {
+		merge = []byte("{}")
+	}
+	patch = append(d.patch, ']')
+
+	return merge, patch, nil
+}
+
+// A differ accumulates a JSON Patch.
+type differ struct {
+	patch []byte
+}
+
+// diff compares the values a and b, found at path, adding operations to
+// d.patch and returning a merge patch, or nil if the values are equal.
+func (d *differ) diff(path []byte, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+
+	ak, acs := children(a)
+	bk, bcs := children(b)
+
+	switch {
+	case ak == ObjectStart && bk == ObjectStart:
+		var m []byte
+
+		for _, c := range acs {
+			if find(bcs, c.key) < 0 {
+				d.op("remove", appendPath(path, c.key), nil)
+				m = appendMember(m, c.key, []byte("null"))
+			}
+		}
+
+		for _, c := range bcs {
+			sub := appendPath(path, c.key)
+			if i := find(acs, c.key); i < 0 {
+				d.op("add", sub, c.value)
+				m = appendMember(m, c.key, c.value)
+			} else if v := d.diff(sub, acs[i].value, c.value); v != nil {
+				m = appendMember(m, c.key, v)
+			}
+		}
+
+		if m == nil {
+			return nil
+		}
+		return append(m, '}')
+
+	case ak == ArrayStart && bk == ArrayStart:
+		n := len(acs)
+		if len(bcs) < n {
+			n = len(bcs)
+		}
+
+		changed := len(acs) != len(bcs)
+		for i := 0; i < n; i++ {
+			sub := strconv.AppendInt(append(path[:len(path):len(path)], '/'), int64(i), 10)
+			if d.diff(sub, acs[i].value, bcs[i].value) != nil {
+				changed = true
+			}
+		}
+
+		// Remove surplus elements back to front, so that the indices
+		// of those remaining don't shift.
+		for i := len(acs) - 1; i >= n; i-- {
+			d.op("remove", strconv.AppendInt(append(path[:len(path):len(path)], '/'), int64(i), 10), nil)
+		}
+		for i := n; i < len(bcs); i++ {
+			d.op("add", append(path[:len(path):len(path)], "/-"...), bcs[i].value)
+		}
+
+		if !changed {
+			return nil
+		}
+		return b
+
+	default:
+		va, _ := decodeBytes(a)
+		vb, _ := decodeBytes(b)
+		if ak == bk && equal(va, vb) {
+			return nil
+		}
+
+		d.op("replace", path, b)
+		return b
+	}
+}
+
+// op appends an operation to the JSON Patch.
+func (d *differ) op(name string, path, value []byte) {
+	if len(d.patch) > 1 {
+		d.patch = append(d.patch, ',')
+	}
+
+	d.patch = append(d.patch, `{"op":"`...)
+	d.patch = append(d.patch, name...)
+	d.patch = append(d.patch, `","path":`...)
+	d.patch = appendQuote(d.patch, path)
+
+	if value != nil {
+		d.patch = append(d.patch, `,"value":`...)
+		d.patch = append(d.patch, value...)
+	}
+
+	d.patch = append(d.patch, '}')
+}
+
+// appendPath appends an escaped reference token to a JSON Pointer, without
+// modifying path itself.
+func appendPath(path []byte, key string) []byte {
+	return appendPointerToken(append(path[:len(path):len(path)], '/'), []byte(key))
+}
+
+// appendMember adds a member to an object under construction, which is
+// started when m is nil and left without a closing brace.
+func appendMember(m []byte, key string, val []byte) []byte {
+	if m == nil {
+		m = append(m, '{')
+	} else {
+		m = append(m, ',')
+	}
+
+	m = appendQuote(m, []byte(key))
+	m = append(m, ':')
+	return append(m, val...)
+}
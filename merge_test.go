@@ -0,0 +1,129 @@
+package jo
+
+import (
+	"testing"
+)
+
+var mergePatchTests = []struct {
+	doc   string
+	patch string
+	out   string
+}{
+	// Examples from RFC 7386, appendix A.
+	{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+	{`{"a":"b"}`, `{"a":null}`, `{}`},
+	{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+	{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+	{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+	{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+	{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+	{`{"a":"b"}`, `["c"]`, `["c"]`},
+	{`{"a":"foo"}`, `null`, `null`},
+	{`{"a":"foo"}`, `"bar"`, `"bar"`},
+	{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+	{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+	{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+
+	// Untouched parts of the document are preserved.
+	{
+		"{\n  \"a\": 1,\n  \"b\": { \"c\": [ 1 ] },\n  \"d\": true\n}\n",
+		`{"a": null, "b": {"e": 2}}`,
+		"{\n  \"b\": { \"c\": [ 1 ],\"e\":2 },\n  \"d\": true\n}\n",
+	},
+}
+
+func TestMergePatch(t *testing.T) {
+	for _, test := range mergePatchTests {
+		out, err := MergePatch([]byte(test.doc), []byte(test.patch))
+		if err != nil {
+			t.Errorf("%#q <- %#q: %s", test.doc, test.patch, err)
+			continue
+		}
+
+		if string(out) != test.out {
+			t.Errorf("%#q <- %#q:", test.doc, test.patch)
+			t.Errorf("  got  %#q", out)
+			t.Errorf("  want %#q", test.out)
+		}
+	}
+}
+
+var diffTests = []struct {
+	a, b  string
+	merge string
+	patch string
+}{
+	{
+		`{"a": 1, "b": [1, 2]}`,
+		`{"b": [1, 2], "a": 1.0}`,
+		`{}`,
+		`[]`,
+	},
+	{
+		`{"a": 1, "b": {"c": "x", "d": true}, "e": []}`,
+		`{"a": 2, "b": {"c": "x", "f": null}, "g": {"h": 1}}`,
+		`{"e":null,"a":2,"b":{"d":null,"f":null},"g":{"h": 1}}`,
+		`[{"op":"remove","path":"/e"},{"op":"replace","path":"/a","value":2},` +
+			`{"op":"remove","path":"/b/d"},{"op":"add","path":"/b/f","value":null},` +
+			`{"op":"add","path":"/g","value":{"h": 1}}]`,
+	},
+	{
+		`{"x": [1, {"y": 2}, 3, 4]}`,
+		`{"x": [1, {"y": 3}]}`,
+		`{"x":[1, {"y": 3}]}`,
+		`[{"op":"replace","path":"/x/1/y","value":3},` +
+			`{"op":"remove","path":"/x/3"},{"op":"remove","path":"/x/2"}]`,
+	},
+	{
+		`[1]`,
+		`[1, "a/b", ["c"]]`,
+		`[1, "a/b", ["c"]]`,
+		`[{"op":"add","path":"/-","value":"a/b"},{"op":"add","path":"/-","value":["c"]}]`,
+	},
+	{
+		`{"a~b": {"c/d": 1}}`,
+		`{"a~b": {"c/d": "1"}}`,
+		`{"a~b":{"c/d":"1"}}`,
+		`[{"op":"replace","path":"/a~0b/c~1d","value":"1"}]`,
+	},
+	{
+		`{"a": 1}`,
+		`[1]`,
+		`[1]`,
+		`[{"op":"replace","path":"","value":[1]}]`,
+	},
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range diffTests {
+		merge, patch, err := Diff([]byte(test.a), []byte(test.b))
+		if err != nil {
+			t.Errorf("%#q, %#q: %s", test.a, test.b, err)
+			continue
+		}
+
+		if string(merge) != test.merge || string(patch) != test.patch {
+			t.Errorf("%#q, %#q:", test.a, test.b)
+			t.Errorf("  got  %#q, %#q", merge, patch)
+			t.Errorf("  want %#q, %#q", test.merge, test.patch)
+			continue
+		}
+
+		// Applying the JSON Patch must turn a into b.
+		out, err := ApplyPatch([]byte(test.a), patch)
+		if err != nil {
+			t.Errorf("%#q <- %#q: %s", test.a, patch, err)
+			continue
+		}
+
+		got, _ := decodeBytes(out)
+		want, _ := decodeBytes([]byte(test.b))
+		if !equal(got, want) {
+			t.Errorf("%#q <- %#q:", test.a, patch)
+			t.Errorf("  got  %#q", out)
+			t.Errorf("  want %#q", test.b)
+		}
+	}
+}
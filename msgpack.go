@@ -0,0 +1,437 @@
+package jo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ToMsgPack converts a single JSON document read from src to MessagePack,
+// and writes it to dst.
+//
+// As MessagePack requires the length of each map and array up front, the
+// encoding of every open object and array is buffered until it ends, at
+// which point its member or element count is known. Only the top-level
+// value's encoding is ever held in memory in its entirety.
+//
+// Integers are encoded in the smallest of MessagePack's integer types which
+// holds them. Other numbers, and integers beyond 64 bits, are encoded as
+// 32-bit floats if that can be done without loss, and as 64-bit floats
+// otherwise.
+func ToMsgPack(dst io.Writer, src io.Reader) error {
+	var w = bufio.NewWriter(dst)
+	var t = newTokenizer(src)
+	var stack []msgPackContainer
+	var buf, str []byte
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			w.Flush()
+			return err
+		}
+
+		if n := len(stack); n > 0 && isValue(tok.kind) {
+			stack[n-1].n++
+		}
+
+		switch tok.kind {
+		case ObjectStart, ArrayStart:
+			// Reuse the buffers of containers which have already ended.
+			if n := len(stack); n < cap(stack) {
+				stack = stack[:n+1]
+				stack[n].n, stack[n].body = 0, stack[n].body[:0]
+			} else {
+				stack = append(stack, msgPackContainer{})
+			}
+			stack[len(stack)-1].array = tok.kind == ArrayStart
+			continue
+		}
+
+		var c msgPackContainer
+		if tok.kind&(ObjectEnd|ArrayEnd) != 0 {
+			c = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		}
+
+		// Values are appended to the encoding of the innermost container,
+		// or written out directly at the top level.
+		out := &buf
+		if n := len(stack); n > 0 {
+			out = &stack[n-1].body
+		}
+
+		switch tok.kind {
+		case ObjectEnd, ArrayEnd:
+			if c.array {
+				*out = appendMsgPackHead(*out, 0x90, 0xdc, 0xdd, c.n)
+			} else {
+				*out = appendMsgPackHead(*out, 0x80, 0xde, 0xdf, c.n)
+			}
+			*out = append(*out, c.body...)
+		case KeyEnd, StringEnd:
+			str = unquote(str[:0], tok.text)
+			*out = appendMsgPackString(*out, str)
+		case NumberEnd:
+			if *out, err = appendMsgPackNumber(*out, tok.text); err != nil {
+				w.Flush()
+				return err
+			}
+		case BoolEnd:
+			if tok.text[0] == 't' {
+				*out = append(*out, 0xc3)
+			} else {
+				*out = append(*out, 0xc2)
+			}
+		case NullEnd:
+			*out = append(*out, 0xc0)
+		}
+
+		if len(stack) == 0 {
+			w.Write(buf)
+			buf = buf[:0]
+		}
+	}
+
+	return w.Flush()
+}
+
+// A msgPackContainer is an object or array being encoded by ToMsgPack.
+type msgPackContainer struct {
+	array bool
+	n     int
+	body  []byte
+}
+
+// appendMsgPackHead appends the header of a map or array with n members or
+// elements, using the fix, 16-bit or 32-bit format as appropriate.
+func appendMsgPackHead(dst []byte, fix, c16, c32 byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, c16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, c32), uint32(n))
+	}
+}
+
+// appendMsgPackString appends s in the smallest string format.
+func appendMsgPackString(dst, s []byte) []byte {
+	switch n := len(s); {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+	return append(dst, s...)
+}
+
+// appendMsgPackNumber appends a JSON number literal in the smallest format
+// which represents it, if possible exactly.
+func appendMsgPackNumber(dst, lit []byte) ([]byte, error) {
+	if bytes.IndexAny(lit, ".eE") < 0 {
+		if n, err := strconv.ParseInt(string(lit), 10, 64); err == nil {
+			return appendMsgPackInt(dst, n), nil
+		}
+		if n, err := strconv.ParseUint(string(lit), 10, 64); err == nil {
+			return binary.BigEndian.AppendUint64(append(dst, 0xcf), n), nil
+		}
+	}
+
+	f, _ := strconv.ParseFloat(string(lit), 64)
+	if math.IsInf(f, 0) {
+		return nil, fmt.Errorf("jo: number %s out of range", lit)
+	}
+
+	if f32 := float32(f); float64(f32) == f {
+		return binary.BigEndian.AppendUint32(append(dst, 0xca), math.Float32bits(f32)), nil
+	}
+	return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(f)), nil
+}
+
+// appendMsgPackInt appends n in the smallest integer format. Non-negative
+// numbers use the unsigned formats.
+func appendMsgPackInt(dst []byte, n int64) []byte {
+	switch {
+	case 0 <= n && n < 128, -32 <= n && n < 0:
+		return append(dst, byte(n))
+	case 0 <= n && n <= math.MaxUint8:
+		return append(dst, 0xcc, byte(n))
+	case 0 <= n && n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(n))
+	case 0 <= n && n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(n))
+	case 0 <= n:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), uint64(n))
+	case math.MinInt8 <= n:
+		return append(dst, 0xd0, byte(n))
+	case math.MinInt16 <= n:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(n))
+	case math.MinInt32 <= n:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(n))
+	}
+}
+
+// Maps and arrays may be nested no deeper than this in MessagePack input.
+const maxMsgPackDepth = 10000
+
+// FromMsgPack converts a single MessagePack object read from src to JSON,
+// and writes it to dst. Any data following the object is rejected.
+//
+// Binary data is rendered as unpadded base64url strings, and timestamps as
+// RFC 3339 strings. Map keys must be strings or integers. Other extension
+// types, and infinite and NaN floats, can't be represented in JSON and are
+// rejected.
+func FromMsgPack(dst io.Writer, src io.Reader) error {
+	d := &msgPackDecoder{r: bufio.NewReader(src), w: newWriter(dst)}
+
+	err := d.value(false)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	} else if err == nil {
+		if _, err = d.r.ReadByte(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = fmt.Errorf("jo: unexpected data after MessagePack object")
+		}
+	}
+
+	if err != nil {
+		d.w.flush()
+		return err
+	}
+	return d.w.flush()
+}
+
+// A msgPackDecoder renders MessagePack objects as JSON.
+type msgPackDecoder struct {
+	r     *bufio.Reader
+	w     *writer
+	depth int
+	buf   []byte
+}
+
+// uint reads an n-byte big-endian unsigned integer.
+func (d *msgPackDecoder) uint(n int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(d.r, b[8-n:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// bytes reads n bytes.
+func (d *msgPackDecoder) bytes(n uint64) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// Copy rather than allocate up front, so that a bogus length can't
+	// exhaust memory.
+	if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// value renders the next object, which must be a string or integer if key
+// is true.
+func (d *msgPackDecoder) value(key bool) error {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	// Classify the format, and read its length or value.
+	var kind byte
+	var n uint64
+	var i int64
+
+	switch {
+	case c < 0x80:
+		kind, n = 'u', uint64(c)
+	case c >= 0xe0:
+		kind, i = 'i', int64(int8(c))
+	case c < 0x90:
+		kind, n = 'm', uint64(c&0x0f)
+	case c < 0xa0:
+		kind, n = 'a', uint64(c&0x0f)
+	case c < 0xc0:
+		kind, n = 's', uint64(c&0x1f)
+	case 0xcc <= c && c <= 0xcf:
+		kind = 'u'
+		n, err = d.uint(1 << (c - 0xcc))
+	case 0xd0 <= c && c <= 0xd3:
+		kind = 'i'
+		size := 1 << (c - 0xd0)
+		n, err = d.uint(size)
+		i = int64(n<<(64-8*size)) >> (64 - 8*size)
+	case 0xd9 <= c && c <= 0xdb:
+		kind = 's'
+		n, err = d.uint(1 << (c - 0xd9))
+	case 0xc4 <= c && c <= 0xc6:
+		kind = 'b'
+		n, err = d.uint(1 << (c - 0xc4))
+	case c == 0xdc || c == 0xdd:
+		kind = 'a'
+		n, err = d.uint(2 << (c - 0xdc))
+	case c == 0xde || c == 0xdf:
+		kind = 'm'
+		n, err = d.uint(2 << (c - 0xde))
+	case 0xd4 <= c && c <= 0xd8:
+		kind, n = 'x', 1<<(c-0xd4)
+	case 0xc7 <= c && c <= 0xc9:
+		kind = 'x'
+		n, err = d.uint(1 << (c - 0xc7))
+	default:
+		kind = c
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if key && kind != 's' && kind != 'u' && kind != 'i' {
+		return fmt.Errorf("jo: unsupported MessagePack map key 0x%02x", c)
+	}
+
+	switch kind {
+	case 'u', 'i':
+		if kind == 'u' {
+			d.buf = strconv.AppendUint(d.buf[:0], n, 10)
+		} else {
+			d.buf = strconv.AppendInt(d.buf[:0], i, 10)
+		}
+		if key {
+			d.w.key(d.buf)
+		} else {
+			d.w.raw(d.buf)
+		}
+
+	case 's', 'b':
+		b, err := d.bytes(n)
+		if err != nil {
+			return err
+		}
+		if kind == 'b' {
+			d.w.str(base64.RawURLEncoding.AppendEncode(nil, b))
+		} else if !utf8.Valid(b) {
+			return fmt.Errorf("jo: invalid UTF-8 in MessagePack string")
+		} else if key {
+			d.w.key(b)
+		} else {
+			d.w.str(b)
+		}
+
+	case 'a', 'm':
+		if d.depth++; d.depth > maxMsgPackDepth {
+			return fmt.Errorf("jo: MessagePack nesting too deep")
+		}
+
+		if kind == 'a' {
+			d.w.begin('[')
+		} else {
+			d.w.begin('{')
+		}
+
+		for j := uint64(0); j < n; j++ {
+			if kind == 'm' {
+				if err := d.value(true); err != nil {
+					return err
+				}
+			}
+			if err := d.value(false); err != nil {
+				return err
+			}
+		}
+
+		if kind == 'a' {
+			d.w.end(']')
+		} else {
+			d.w.end('}')
+		}
+		d.depth--
+
+	case 'x':
+		return d.ext(n)
+
+	case 0xc0:
+		d.w.raw([]byte("null"))
+	case 0xc2:
+		d.w.raw([]byte("false"))
+	case 0xc3:
+		d.w.raw([]byte("true"))
+
+	case 0xca, 0xcb:
+		var f float64
+		if kind == 0xca {
+			n, err = d.uint(4)
+			f = float64(math.Float32frombits(uint32(n)))
+		} else {
+			n, err = d.uint(8)
+			f = math.Float64frombits(n)
+		}
+
+		if err != nil {
+			return err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("jo: MessagePack float %v can't be represented in JSON", f)
+		}
+		d.w.raw(appendECMANumber(d.buf[:0], f))
+
+	default:
+		return fmt.Errorf("jo: invalid MessagePack format 0x%02x", c)
+	}
+
+	return nil
+}
+
+// ext renders an extension object with n bytes of data. Only timestamps
+// are supported.
+func (d *msgPackDecoder) ext(n uint64) error {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if int8(typ) != -1 || n != 4 && n != 8 && n != 12 {
+		return fmt.Errorf("jo: unsupported MessagePack extension type %d", int8(typ))
+	}
+
+	var sec, nsec uint64
+
+	switch n {
+	case 4:
+		sec, err = d.uint(4)
+	case 8:
+		if sec, err = d.uint(8); err == nil {
+			nsec, sec = sec>>34, sec&(1<<34-1)
+		}
+	case 12:
+		if nsec, err = d.uint(4); err == nil {
+			sec, err = d.uint(8)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ts := time.Unix(int64(sec), int64(nsec)).UTC()
+	d.w.str(ts.AppendFormat(d.buf[:0], time.RFC3339Nano))
+	return nil
+}
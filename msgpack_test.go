@@ -0,0 +1,125 @@
+package jo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+var toMsgPackTests = []struct {
+	in  string
+	out string
+}{
+	{`0`, `00`},
+	{`127`, `7f`},
+	{`128`, `cc80`},
+	{`256`, `cd0100`},
+	{`65536`, `ce00010000`},
+	{`4294967296`, `cf0000000100000000`},
+	{`18446744073709551615`, `cfffffffffffffffff`},
+	{`-1`, `ff`},
+	{`-32`, `e0`},
+	{`-33`, `d0df`},
+	{`-129`, `d1ff7f`},
+	{`-32769`, `d2ffff7fff`},
+	{`-2147483649`, `d3ffffffff7fffffff`},
+	{`1.5`, `ca3fc00000`},
+	{`1e3`, `ca447a0000`},
+	{`1.1`, `cb3ff199999999999a`},
+	{`18446744073709551616`, `ca5f800000`},
+	{`true`, `c3`},
+	{`false`, `c2`},
+	{`null`, `c0`},
+	{`""`, `a0`},
+	{`"a\n"`, `a2610a`},
+	{`"` + strings.Repeat("x", 32) + `"`, `d920` + strings.Repeat("78", 32)},
+	{`[]`, `90`},
+	{`[1, [2, 3], {}]`, `9301920203` + `80`},
+	{`{"a": 1, "b": [true, null]}`, `82a16101a16292c3c0`},
+	{`[` + strings.Repeat(`0,`, 15) + `0]`, `dc0010` + strings.Repeat("00", 16)},
+	{`[[1, 2], [3], [[` + strings.Repeat(`0,`, 15) + `0]]]`, `93920102910391dc0010` + strings.Repeat("00", 16)},
+	{`{"a": [{"b": [[]]}, {}], "c": {"d": 1}}`, `82a1619281a162919080a16381a16401`},
+}
+
+func TestToMsgPack(t *testing.T) {
+	for _, test := range toMsgPackTests {
+		var buf bytes.Buffer
+
+		if err := ToMsgPack(&buf, strings.NewReader(test.in)); err != nil {
+			t.Errorf("%#q: %s", test.in, err)
+			continue
+		}
+
+		if got := hex.EncodeToString(buf.Bytes()); got != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %s", got)
+			t.Errorf("  want %s", test.out)
+		}
+	}
+}
+
+var fromMsgPackTests = []struct {
+	in  string
+	out string
+	err string
+}{
+	{`d0df`, `-33`, ``},
+	{`d3ffffffff7fffffff`, `-2147483649`, ``},
+	{`cfffffffffffffffff`, `18446744073709551615`, ``},
+	{`ca3fc00000`, `1.5`, ``},
+	{`c40301ff02`, `"Af8C"`, ``},
+	{`82a16101a16292c3c0`, `{"a":1,"b":[true,null]}`, ``},
+	{`8201a178ffa179`, `{"1":"x","-1":"y"}`, ``},
+	{`d6ff5d2b0b80`, `"2019-07-14T11:01:20Z"`, ``},
+	{`d7ff0000000c5d2b0b80`, `"2019-07-14T11:01:20.000000003Z"`, ``},
+	{`c70cff000000010000000000000001`, `"1970-01-01T00:00:01.000000001Z"`, ``},
+	{`d40100`, ``, `jo: unsupported MessagePack extension type 1`},
+	{`81c001`, ``, `jo: unsupported MessagePack map key 0xc0`},
+	{`c1`, ``, `jo: invalid MessagePack format 0xc1`},
+	{`cb7ff0000000000000`, ``, `jo: MessagePack float +Inf can't be represented in JSON`},
+	{`92a0`, ``, `unexpected EOF`},
+	{`a3616263`, `"abc"`, ``},
+	{`0102c1`, ``, `jo: unexpected data after MessagePack object`},
+	{`910102`, ``, `jo: unexpected data after MessagePack object`},
+}
+
+func TestFromMsgPack(t *testing.T) {
+	for _, test := range fromMsgPackTests {
+		var buf bytes.Buffer
+		var msg string
+
+		in, _ := hex.DecodeString(test.in)
+		if err := FromMsgPack(&buf, bytes.NewReader(in)); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || msg == "" && buf.String() != test.out {
+			t.Errorf("%s:", test.in)
+			t.Errorf("  got  %#q, %q", buf.String(), msg)
+			t.Errorf("  want %#q, %q", test.out, test.err)
+		}
+	}
+}
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	for _, in := range roundTripTests {
+		var mp, out bytes.Buffer
+
+		if err := ToMsgPack(&mp, strings.NewReader(in)); err != nil {
+			t.Errorf("ToMsgPack(%#q): %s", in, err)
+			continue
+		}
+		if err := FromMsgPack(&out, &mp); err != nil {
+			t.Errorf("FromMsgPack(%#q): %s", in, err)
+			continue
+		}
+
+		a, _ := decodeBytes([]byte(in))
+		b, err := decodeBytes(out.Bytes())
+		if err != nil || !equal(a, b) {
+			t.Errorf("%#q:", in)
+			t.Errorf("  got  %#q", out.String())
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package jo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A PatchError describes a JSON Patch operation which could not be applied.
+type PatchError struct {
+	// Index of the operation within the patch, and its name.
+	Index int
+	Op    string
+
+	Message string
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch operation %d (%s): %s", e.Index, e.Op, e.Message)
+}
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to doc and returns the result.
+// The add, remove, replace, move, copy and test operations are supported.
+//
+// Every operation is carried out by splicing raw bytes, so the parts of doc
+// which aren't touched by the patch are preserved exactly, whitespace and
+// all. Values are copied from the patch (or, for move and copy, from doc)
+// in their original form as well.
+//
+// Operations are applied in order, and the first which fails aborts the
+// entire patch with a *PatchError. If an operation has several members with
+// the same name, the first one is used.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	if _, err := decodeBytes(doc); err != nil {
+		return nil, err
+	}
+
+	v, err := decodeBytes(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jo: patch is not an array")
+	}
+
+	_, raw := children(trim(patch))
+
+	for i, op := range ops {
+		o, ok := op.(object)
+		if !ok {
+			return nil, &PatchError{i, "", "operation is not an object"}
+		}
+
+		p := &patchOp{index: i}
+		name, _ := o.get("op")
+		if p.name, ok = name.(string); !ok {
+			return nil, &PatchError{i, "", `missing or invalid "op" member`}
+		}
+
+		// Look up the raw value, if any. As with the other members, the
+		// first occurrence wins.
+		_, members := children(raw[i].value)
+		for _, m := range members {
+			if m.key == "value" {
+				p.value = m.value
+				break
+			}
+		}
+
+		if doc, err = p.apply(doc, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// A patchOp is a single operation being applied.
+type patchOp struct {
+	index int
+	name  string
+	value []byte
+}
+
+func (p *patchOp) fail(format string, args ...interface{}) error {
+	return &PatchError{p.index, p.name, fmt.Sprintf(format, args...)}
+}
+
+// pointer reads and parses one of the operation's JSON Pointers.
+func (p *patchOp) pointer(o object, key string) ([]string, error) {
+	v, _ := o.get(key)
+
+	str, ok := v.(string)
+	if !ok {
+		return nil, p.fail("missing or invalid %q member", key)
+	}
+
+	path, err := SplitPointer(str)
+	if err != nil {
+		return nil, p.fail("%s", err)
+	}
+
+	return path, nil
+}
+
+func (p *patchOp) apply(doc []byte, o object) ([]byte, error) {
+	path, err := p.pointer(o, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch p.name {
+	case "add", "replace", "test":
+		if p.value == nil {
+			return nil, p.fail(`missing "value" member`)
+		}
+	case "move", "copy":
+		from, err := p.pointer(o, "from")
+		if err != nil {
+			return nil, err
+		}
+
+		start, end, err := locate(doc, from)
+		if err != nil {
+			return nil, p.fail("%s", err)
+		}
+		p.value = doc[start:end]
+
+		if p.name == "copy" {
+			break
+		}
+		if isPrefix(from, path) {
+			if len(from) == len(path) {
+				return doc, nil
+			}
+			return nil, p.fail("cannot move a value into itself")
+		}
+
+		// The value is about to be removed from doc.
+		p.value = append([]byte(nil), p.value...)
+		if doc, err = p.remove(doc, from); err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, p.fail("unknown operation")
+	}
+
+	switch p.name {
+	case "add", "move", "copy":
+		return p.add(doc, path)
+	case "remove":
+		return p.remove(doc, path)
+	case "replace":
+		start, end, err := locate(doc, path)
+		if err != nil {
+			return nil, p.fail("%s", err)
+		}
+		return splice(doc, start, end, p.value), nil
+	default:
+		start, end, err := locate(doc, path)
+		if err != nil {
+			return nil, p.fail("%s", err)
+		}
+
+		a, _ := decodeBytes(doc[start:end])
+		b, _ := decodeBytes(p.value)
+		if !equal(a, b) {
+			return nil, p.fail("test failed at %q", joinPointer(path))
+		}
+		return doc, nil
+	}
+}
+
+// add inserts p.value at path.
+func (p *patchOp) add(doc []byte, path []string) ([]byte, error) {
+	if len(path) == 0 {
+		return append([]byte(nil), p.value...), nil
+	}
+
+	start, end, err := locate(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, p.fail("%s", err)
+	}
+
+	v := doc[start:end]
+	kind, cs := children(v)
+	tok := path[len(path)-1]
+
+	switch kind {
+	case ObjectStart:
+		v = withMember(v, cs, tok, p.value)
+	case ArrayStart:
+		i := len(cs)
+		if tok != "-" {
+			if i, err = index(tok, len(cs)+1); err != nil {
+				return nil, p.fail("%s at %q", err, joinPointer(path))
+			}
+		}
+		v = withElement(v, cs, i, p.value)
+	default:
+		return nil, p.fail("no object or array at %q", joinPointer(path[:len(path)-1]))
+	}
+
+	return splice(doc, start, end, v), nil
+}
+
+// remove deletes the value at path, along with its key and a comma.
+func (p *patchOp) remove(doc []byte, path []string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, p.fail("cannot remove the root value")
+	}
+
+	start, end, err := locate(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, p.fail("%s", err)
+	}
+
+	v := doc[start:end]
+	_, cs := children(v)
+
+	i, err := child(v, cs, path[len(path)-1])
+	if err != nil {
+		return nil, p.fail("%s at %q", err, joinPointer(path))
+	}
+
+	return splice(doc, start, end, without(v, cs, i)), nil
+}
+
+// withMember sets the value of a member of the encoded object v, whose
+// members are cs, adding the member if necessary.
+func withMember(v []byte, cs []span, key string, val []byte) []byte {
+	if i := find(cs, key); i >= 0 {
+		return splice(v, cs[i].start, cs[i].end, val)
+	}
+
+	var ins []byte
+	var pos int
+
+	if n := len(cs); n > 0 {
+		pos = cs[n-1].end
+		ins = append(ins, ',')
+	} else {
+		pos = len(v) - 1
+	}
+
+	ins = appendQuote(ins, []byte(key))
+	ins = append(ins, ':')
+	ins = append(ins, val...)
+
+	return splice(v, pos, pos, ins)
+}
+
+// withElement inserts val as the i-th element of the encoded array v, whose
+// elements are cs.
+func withElement(v []byte, cs []span, i int, val []byte) []byte {
+	var ins []byte
+	var pos int
+
+	switch {
+	case i < len(cs):
+		pos = cs[i].from
+		ins = append(append(ins, val...), ',')
+	case i > 0:
+		pos = cs[i-1].end
+		ins = append(append(ins, ','), val...)
+	default:
+		pos = len(v) - 1
+		ins = val
+	}
+
+	return splice(v, pos, pos, ins)
+}
+
+// without removes the i-th member or element from the encoded object or
+// array v, whose children are cs.
+func without(v []byte, cs []span, i int) []byte {
+	// Prefer removing the comma following the value, so that the
+	// whitespace preceding it is kept.
+	switch {
+	case i+1 < len(cs):
+		return splice(v, cs[i].from, cs[i+1].from, nil)
+	case i > 0:
+		return splice(v, cs[i-1].end, cs[i].end, nil)
+	default:
+		return splice(v, cs[i].from, cs[i].end, nil)
+	}
+}
+
+// A span describes a member or element of an object or array.
+type span struct {
+	// Unescaped key, for object members.
+	key string
+
+	// Offset of the key (or of the value, for array elements), and the
+	// boundaries of the raw value.
+	from, start, end int
+
+	value []byte
+}
+
+// children lists the members or elements of an encoded object or array,
+// which must be valid JSON without surrounding whitespace. The kind of v's
+// first token is returned as well.
+func children(v []byte) (Event, []span) {
+	var cs []span
+
+	kind := each(v, 0, func(key []byte, from, start, end int) bool {
+		cs = append(cs, span{string(unquote(nil, key)), from, start, end, v[start:end]})
+		return true
+	})
+
+	return kind, cs
+}
+
+// locate returns the boundaries of the value at path within doc.
+func locate(doc []byte, path []string) (int, int, error) {
+	v := trim(doc)
+	start := len(doc) - len(bytes.TrimLeft(doc, " \t\r\n"))
+	end := start + len(v)
+
+	for i, tok := range path {
+		_, cs := children(doc[start:end])
+
+		j, err := child(doc[start:end], cs, tok)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s at %q", err, joinPointer(path[:i+1]))
+		}
+
+		start, end = start+cs[j].start, start+cs[j].end
+	}
+
+	return start, end, nil
+}
+
+// child finds the member or element of v referred to by tok.
+func child(v []byte, cs []span, tok string) (int, error) {
+	switch v[0] {
+	case '{':
+		if i := find(cs, tok); i >= 0 {
+			return i, nil
+		}
+		return 0, fmt.Errorf("no such member")
+	case '[':
+		return index(tok, len(cs))
+	default:
+		return 0, fmt.Errorf("no object or array")
+	}
+}
+
+// find returns the index of the last member with the specified key, or -1.
+func find(cs []span, key string) int {
+	for i := len(cs) - 1; i >= 0; i-- {
+		if cs[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// index parses an array index, which must be less than n.
+func index(tok string, n int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || tok[0] == '+' || len(tok) > 1 && tok[0] == '0' {
+		return 0, fmt.Errorf("invalid array index")
+	}
+	if i >= n {
+		return 0, fmt.Errorf("array index out of bounds")
+	}
+	return i, nil
+}
+
+// splice replaces doc[start:end] with ins, returning a new slice.
+func splice(doc []byte, start, end int, ins []byte) []byte {
+	buf := make([]byte, 0, len(doc)-(end-start)+len(ins))
+	buf = append(buf, doc[:start]...)
+	buf = append(buf, ins...)
+	return append(buf, doc[end:]...)
+}
+
+// trim strips leading and trailing whitespace.
+func trim(v []byte) []byte {
+	return bytes.Trim(v, " \t\r\n")
+}
+
+// isPrefix reports whether path a is a prefix of (or equal to) path b.
+func isPrefix(a, b []string) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitPointer splits a JSON Pointer (RFC 6901) into its unescaped
+// reference tokens. The empty pointer, referring to the whole document,
+// yields no tokens.
+func SplitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("JSON Pointer %q doesn't start with '/'", ptr)
+	}
+
+	toks := strings.Split(ptr[1:], "/")
+	for i, tok := range toks {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || tok[j+1] != '0' && tok[j+1] != '1') {
+				return nil, fmt.Errorf("invalid escape sequence in JSON Pointer %q", ptr)
+			}
+		}
+		toks[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+	}
+
+	return toks, nil
+}
+
+// joinPointer is the inverse of SplitPointer.
+func joinPointer(path []string) string {
+	var buf []byte
+	for _, tok := range path {
+		buf = append(buf, '/')
+		buf = appendPointerToken(buf, []byte(tok))
+	}
+	return string(buf)
+}
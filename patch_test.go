@@ -0,0 +1,229 @@
+package jo
+
+import (
+	"reflect"
+	"testing"
+)
+
+var applyPatchTests = []struct {
+	doc   string
+	patch string
+	out   string
+	err   string
+}{
+	// Examples from RFC 6902, appendix A.
+	{
+		`{"foo": "bar"}`,
+		`[{"op": "add", "path": "/baz", "value": "qux"}]`,
+		`{"foo": "bar","baz":"qux"}`,
+		``,
+	},
+	{
+		`{"foo": ["bar", "baz"]}`,
+		`[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+		`{"foo": ["bar", "qux","baz"]}`,
+		``,
+	},
+	{
+		`{"baz": "qux", "foo": "bar"}`,
+		`[{"op": "remove", "path": "/baz"}]`,
+		`{"foo": "bar"}`,
+		``,
+	},
+	{
+		`{"foo": ["bar", "qux", "baz"]}`,
+		`[{"op": "remove", "path": "/foo/1"}]`,
+		`{"foo": ["bar", "baz"]}`,
+		``,
+	},
+	{
+		`{"baz": "qux", "foo": "bar"}`,
+		`[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+		`{"baz": "boo", "foo": "bar"}`,
+		``,
+	},
+	{
+		`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+		`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+		`{"foo": {"bar": "baz"}, "qux": {"corge": "grault","thud":"fred"}}`,
+		``,
+	},
+	{
+		`{"foo": ["all", "grass", "cows", "eat"]}`,
+		`[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+		`{"foo": ["all", "cows", "eat","grass"]}`,
+		``,
+	},
+	{
+		`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2.0}]`,
+		`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		``,
+	},
+	{
+		`{"baz": "qux"}`,
+		`[{"op": "test", "path": "/baz", "value": "bar"}]`,
+		``,
+		`patch operation 0 (test): test failed at "/baz"`,
+	},
+	{
+		`{"foo": "bar"}`,
+		`[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+		`{"foo": "bar","child":{"grandchild": {}}}`,
+		``,
+	},
+	{
+		`{"foo": "bar"}`,
+		`[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		``,
+		`patch operation 0 (add): no such member at "/baz"`,
+	},
+	{
+		`{"/": 9, "~1": 10}`,
+		`[{"op": "test", "path": "/~01", "value": 10}]`,
+		`{"/": 9, "~1": 10}`,
+		``,
+	},
+	{
+		`{"foo": ["bar"]}`,
+		`[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+		`{"foo": ["bar",["abc", "def"]]}`,
+		``,
+	},
+
+	// Untouched parts of the document are preserved.
+	{
+		"{\n  \"a\": [ 1,  2 ],\n  \"b\": { },\n  \"c\": 1.50\n}\n",
+		`[
+			{"op": "add", "path": "/b/x", "value": [ true ]},
+			{"op": "add", "path": "/a/-", "value": 3},
+			{"op": "remove", "path": "/c"},
+			{"op": "copy", "from": "/a", "path": "/d"}
+		]`,
+		"{\n  \"a\": [ 1,  2,3 ],\n  \"b\": { \"x\":[ true ]},\"d\":[ 1,  2,3 ]\n}\n",
+		``,
+	},
+	{
+		`[1, 2]`,
+		`[{"op": "add", "path": "", "value": {"new": "root"}}]`,
+		`{"new": "root"}`,
+		``,
+	},
+	{
+		`[]`,
+		`[{"op": "add", "path": "/0", "value": "x"}, {"op": "add", "path": "/0", "value": "y"}]`,
+		`["y","x"]`,
+		``,
+	},
+	{
+		`{"a": 1}`,
+		`[{"op": "remove", "path": "/a"}]`,
+		`{}`,
+		``,
+	},
+
+	// Errors.
+	{
+		`[1, 2]`,
+		`[{"op": "add", "path": "/3", "value": 0}]`,
+		``,
+		`patch operation 0 (add): array index out of bounds at "/3"`,
+	},
+	{
+		`[1, 2]`,
+		`[{"op": "remove", "path": "/01"}]`,
+		``,
+		`patch operation 0 (remove): invalid array index at "/01"`,
+	},
+	{
+		`{"a": {"b": 1}}`,
+		`[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+		``,
+		`patch operation 0 (move): cannot move a value into itself`,
+	},
+	{
+		`{}`,
+		`[{"op": "add", "path": "/a"}]`,
+		``,
+		`patch operation 0 (add): missing "value" member`,
+	},
+	{
+		`{}`,
+		`[{"op": "frob", "path": "/a"}]`,
+		``,
+		`patch operation 0 (frob): unknown operation`,
+	},
+	{
+		`{}`,
+		`[{"op": "add", "path": "a", "value": 1}]`,
+		``,
+		`patch operation 0 (add): JSON Pointer "a" doesn't start with '/'`,
+	},
+	{
+		`{}`,
+		`[{"op": "add", "path": "/a", "value": 1, "op": "test", "path": "/b", "value": 2}]`,
+		`{"a":1}`,
+		``,
+	},
+	{
+		`{}`,
+		`[{"op": "add", "path": "/a", "valu\u0065": [1], "value": 2}]`,
+		`{"a":[1]}`,
+		``,
+	},
+	{
+		`{}`,
+		`{"op": "add"}`,
+		``,
+		`jo: patch is not an array`,
+	},
+}
+
+func TestApplyPatch(t *testing.T) {
+	for _, test := range applyPatchTests {
+		out, err := ApplyPatch([]byte(test.doc), []byte(test.patch))
+
+		var msg string
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if string(out) != test.out || msg != test.err {
+			t.Errorf("%#q <- %#q:", test.doc, test.patch)
+			t.Errorf("  got  %#q, %q", out, msg)
+			t.Errorf("  want %#q, %q", test.out, test.err)
+		}
+	}
+}
+
+var splitPointerTests = []struct {
+	ptr  string
+	toks []string
+	err  string
+}{
+	{``, nil, ``},
+	{`/`, []string{""}, ``},
+	{`/a/0`, []string{"a", "0"}, ``},
+	{`/a~1b/c~0d`, []string{"a/b", "c~d"}, ``},
+	{`/~01`, []string{"~1"}, ``},
+	{`a`, nil, `JSON Pointer "a" doesn't start with '/'`},
+	{`/a~2b`, nil, `invalid escape sequence in JSON Pointer "/a~2b"`},
+	{`/a~`, nil, `invalid escape sequence in JSON Pointer "/a~"`},
+}
+
+func TestSplitPointer(t *testing.T) {
+	for _, test := range splitPointerTests {
+		toks, err := SplitPointer(test.ptr)
+
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || !reflect.DeepEqual(toks, test.toks) {
+			t.Errorf("SplitPointer(%#q):", test.ptr)
+			t.Errorf("  got  %q, %q", toks, msg)
+			t.Errorf("  want %q, %q", test.toks, test.err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package jo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A Projection selects parts of documents by path, as for sparse fieldsets.
+type Projection struct {
+	paths [][]string
+	deny  bool
+}
+
+// Allow returns a Projection which keeps only the values at the given
+// paths, along with everything below them and the objects and arrays
+// leading up to them.
+//
+// Paths are written either as JSON Pointers, or as sequences of keys and
+// indices separated by dots, such as "address.city". In either form the
+// wildcard "*" matches any key or index, so that "items.*.id" selects the
+// id member of every element of the items array.
+func Allow(paths ...string) (*Projection, error) {
+	return newProjection(paths, false)
+}
+
+// Deny returns a Projection which removes the values at the given paths,
+// and keeps everything else. Paths are written as for Allow.
+func Deny(paths ...string) (*Projection, error) {
+	return newProjection(paths, true)
+}
+
+func newProjection(paths []string, deny bool) (*Projection, error) {
+	p := &Projection{deny: deny}
+
+	var err error
+	if p.paths, err = parsePaths(paths); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// parsePaths parses paths written either as JSON Pointers or as keys and
+// indices separated by dots.
+func parsePaths(strs []string) ([][]string, error) {
+	var paths [][]string
+
+	for _, str := range strs {
+		var path []string
+		var err error
+
+		if strings.HasPrefix(str, "/") {
+			path, err = SplitPointer(str)
+		} else if str != "" {
+			path = strings.Split(str, ".")
+		} else {
+			err = fmt.Errorf("empty path")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// Filter copies a single document from src to dst, leaving out the members
+// and elements excluded by the Projection. Commas are adjusted to keep the
+// output valid; everything else is copied verbatim. The document is
+// processed as a stream, without ever being held in memory as a whole.
+//
+// Output written before a syntax error is encountered is not retracted.
+func (p *Projection) Filter(dst io.Writer, src io.Reader) error {
+	var w = bufio.NewWriter(dst)
+	var st stream
+
+	// Whitespace, keys and colons preceding the next member or element,
+	// held back until it's known whether it will be kept.
+	var pending []byte
+
+	// Output state of each enclosing object or array.
+	var outer []output
+
+	// Depth of the value being left out, or -1.
+	var skip = -1
+
+	// Whether a string, number or literal is being copied.
+	var inScalar bool
+
+	err := st.run(src, func(ev Event, c int) error {
+		depth := len(st.stack)
+
+		if skip >= 0 {
+			if ev&End == 0 || ev&End == KeyEnd || depth != skip {
+				return nil
+			}
+			skip = -1
+		} else if end := ev & End; end == ObjectEnd || end == ArrayEnd {
+			outer = outer[:len(outer)-1]
+		} else if end != 0 && end != KeyEnd {
+			inScalar = false
+		}
+
+		if start := ev & Start; start != 0 && start != KeyStart {
+			if depth > 0 {
+				o := &outer[depth-1]
+				n := len(pending) - len(bytes.TrimLeft(pending, " \t\r\n"))
+
+				// Remember the whitespace preceding the first member or
+				// element, for the first one which is actually written.
+				if o.lead == nil {
+					o.lead = append([]byte{}, pending[:n]...)
+				}
+
+				if !p.keep(&st, start) {
+					pending = pending[:0]
+					skip = depth
+					return nil
+				}
+
+				if o.written {
+					w.WriteByte(',')
+					w.Write(pending)
+				} else {
+					w.Write(o.lead)
+					w.Write(pending[n:])
+				}
+
+				pending = pending[:0]
+				o.written = true
+			}
+
+			if start == ObjectStart || start == ArrayStart {
+				outer = append(outer, output{})
+			} else {
+				inScalar = true
+			}
+		} else if c >= 0 && depth > 0 && !inScalar {
+			// Between members or elements.
+			switch {
+			case st.inKey:
+				pending = append(pending, byte(c))
+				return nil
+			case c == ',':
+				return nil
+			case c == '}' || c == ']':
+				w.Write(pending)
+				pending = pending[:0]
+			default:
+				pending = append(pending, byte(c))
+				return nil
+			}
+		}
+
+		if c >= 0 {
+			w.WriteByte(byte(c))
+		}
+		return nil
+	})
+
+	if err != nil {
+		w.Flush()
+		return err
+	}
+	return w.Flush()
+}
+
+// An output describes an object or array being written by Filter.
+type output struct {
+	// Whether a member or element has been written, meaning the next one
+	// needs a comma.
+	written bool
+
+	// Whitespace preceding the first member or element in the input.
+	lead []byte
+}
+
+// keep reports whether the value at the stream's current location, which
+// starts with an event of the given kind, is part of the projection.
+func (p *Projection) keep(st *stream, start Event) bool {
+	if len(st.stack) == 0 {
+		return true
+	}
+
+	for _, path := range p.paths {
+		if !st.prefix(path) {
+			continue
+		}
+
+		switch {
+		case p.deny:
+			if len(path) == len(st.stack) {
+				return false
+			}
+		case len(path) <= len(st.stack):
+			return true
+		case start == ObjectStart || start == ArrayStart:
+			// The value leads up to one which is allowed.
+			return true
+		}
+	}
+
+	return p.deny
+}
@@ -0,0 +1,97 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var projectionTests = []struct {
+	deny  bool
+	paths []string
+	in    string
+	out   string
+}{
+	{
+		false,
+		[]string{"id", "name", "address.city"},
+		`{"id": 1, "secret": "x", "name": "Ann", "address": {"street": "Main", "city": "Oslo"}, "tags": []}`,
+		`{"id": 1, "name": "Ann", "address": {"city": "Oslo"}}`,
+	},
+	{
+		false,
+		[]string{"items.*.id"},
+		`{"total": 2, "items": [{"id": 1, "x": [1]}, {"y": 2}, {"x": 3, "id": 3}]}`,
+		`{"items": [{"id": 1}, {}, {"id": 3}]}`,
+	},
+	{
+		false,
+		[]string{"/a~1b/1"},
+		`{"a/b": [10, 11, 12], "a": {"b": [1, 2]}}`,
+		`{"a/b": [11]}`,
+	},
+	{
+		false,
+		[]string{"a.b"},
+		`{"a": 5, "c": {"b": 1}}`,
+		`{}`,
+	},
+	{
+		false,
+		[]string{"a"},
+		"{\n  \"z\": 0,\n  \"a\": {\"x,\": \"}\"},\n  \"b\": 2\n}\n",
+		"{\n  \"a\": {\"x,\": \"}\"}\n}\n",
+	},
+	{
+		true,
+		[]string{"password", "users.*.email"},
+		`{"users": [{"name": "a", "email": "b"}, {"email": "c", "name": "d"}], "password": "x"}`,
+		`{"users": [{"name": "a"}, {"name": "d"}]}`,
+	},
+	{
+		true,
+		[]string{"1", "3"},
+		`[0, 1, 2, 3]`,
+		`[0, 2]`,
+	},
+	{
+		true,
+		[]string{"*"},
+		"[\n  1,\n  2\n]",
+		"[\n]",
+	},
+	{
+		true,
+		[]string{"x"},
+		`"scalar"`,
+		`"scalar"`,
+	},
+}
+
+func TestProjection(t *testing.T) {
+	for _, test := range projectionTests {
+		var p *Projection
+		var err error
+		var buf bytes.Buffer
+
+		if test.deny {
+			p, err = Deny(test.paths...)
+		} else {
+			p, err = Allow(test.paths...)
+		}
+		if err == nil {
+			err = p.Filter(&buf, strings.NewReader(test.in))
+		}
+
+		if err != nil {
+			t.Errorf("%q %#q: %s", test.paths, test.in, err)
+			continue
+		}
+
+		if buf.String() != test.out {
+			t.Errorf("%q %#q:", test.paths, test.in)
+			t.Errorf("  got  %#q", buf.String())
+			t.Errorf("  want %#q", test.out)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package jo
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// unquote appends the contents of a string literal to dst, resolving escape
+// sequences. The literal is assumed to be valid, as reported by a Scanner.
+// Unpaired surrogates are replaced with U+FFFD.
+func unquote(dst, lit []byte) []byte {
+	if len(lit) < 2 {
+		return dst
+	}
+
+	for i := 1; i < len(lit)-1; i++ {
+		c := lit[i]
+		if c != '\\' {
+			dst = append(dst, c)
+			continue
+		}
+
+		i++
+
+		switch lit[i] {
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r := hex4(lit[i+1:])
+			i += 4
+
+			if utf16.IsSurrogate(r) {
+				r2 := utf8.RuneError
+				if i+6 < len(lit) && lit[i+1] == '\\' && lit[i+2] == 'u' {
+					r2 = hex4(lit[i+3:])
+				}
+				if r = utf16.DecodeRune(r, r2); r != utf8.RuneError {
+					i += 6
+				}
+			}
+
+			dst = appendRune(dst, r)
+		default:
+			dst = append(dst, lit[i])
+		}
+	}
+
+	return dst
+}
+
+// hex4 decodes the four hexadecimal digits at the start of b.
+func hex4(b []byte) rune {
+	var r rune
+
+	for _, c := range b[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		default:
+			c -= 'A' - 10
+		}
+		r = r<<4 | rune(c)
+	}
+
+	return r
+}
+
+// appendRune appends the UTF-8 encoding of r to dst.
+func appendRune(dst []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(dst, buf[:n]...)
+}
+
+// appendQuote appends s to dst as a string literal, escaping only what must
+// be escaped: quotation marks, backslashes and control characters.
+func appendQuote(dst []byte, s []byte) []byte {
+	dst = append(dst, '"')
+
+	for _, c := range s {
+		if c >= 0x20 && c != '"' && c != '\\' {
+			dst = append(dst, c)
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, '\\', 'b')
+		case '\f':
+			dst = append(dst, '\\', 'f')
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+		}
+	}
+
+	return append(dst, '"')
+}
+
+const hexDigits = "0123456789abcdef"
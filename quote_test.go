@@ -0,0 +1,52 @@
+package jo
+
+import (
+	"testing"
+)
+
+var unquoteTests = []struct {
+	in  string
+	out string
+}{
+	{`""`, ``},
+	{`"foo"`, `foo`},
+	{`"\"\\\/\b\f\n\r\t"`, "\"\\/\b\f\n\r\t"},
+	{`"☃ = ☃"`, "☃ = ☃"},
+	{`"\ud83d\ude00"`, "\U0001F600"},
+	{`"\ud83d"`, "�"},
+	{`"\ud83dx"`, "�x"},
+	{`"\ude00\ud83d"`, "��"},
+	{`"\ud83dA"`, "�A"},
+}
+
+func TestUnquote(t *testing.T) {
+	for _, test := range unquoteTests {
+		out := string(unquote(nil, []byte(test.in)))
+		if out != test.out {
+			t.Errorf("unquote(%#q):", test.in)
+			t.Errorf("  got  %q", out)
+			t.Errorf("  want %q", test.out)
+		}
+	}
+}
+
+var quoteTests = []struct {
+	in  string
+	out string
+}{
+	{``, `""`},
+	{`foo`, `"foo"`},
+	{"\"\\/\b\f\n\r\t\x00\x1f", `"\"\\/\b\f\n\r\t\u0000\u001f"`},
+	{"☃ ", "\"☃ \""},
+}
+
+func TestAppendQuote(t *testing.T) {
+	for _, test := range quoteTests {
+		out := string(appendQuote(nil, []byte(test.in)))
+		if out != test.out {
+			t.Errorf("appendQuote(%q):", test.in)
+			t.Errorf("  got  %#q", out)
+			t.Errorf("  want %#q", test.out)
+		}
+	}
+}
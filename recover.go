@@ -0,0 +1,88 @@
+package jo
+
+import (
+	"reflect"
+)
+
+// Contexts in which scanning can resume after a syntax error.
+const (
+	syncTop = iota
+	syncObject
+	syncArray
+)
+
+// resync puts the Scanner in recovery after a syntax error caused by c.
+// The innermost enclosing object or array is determined by looking at the
+// state function which failed and the stack of scheduled states.
+func (s *Scanner) resync(c byte) {
+	switch fn := s.failed; {
+	case same(fn, beforeFirstObjectKey), same(fn, afterObjectKey),
+		same(fn, afterObjectValue), same(fn, afterObjectComma):
+		s.sync = syncObject
+	case same(fn, afterArrayElement):
+		s.sync = syncArray
+	case same(fn, afterTopValue):
+		s.sync = syncTop
+	default:
+		// The error occurred inside a value (or, in the case of
+		// beforeFirstArrayElement, right before one), meaning the top of
+		// the stack is the state scheduled to follow that value.
+		n := len(s.stack) - 1
+		switch top := s.stack[n]; {
+		case same(top, afterObjectKey), same(top, afterObjectValue):
+			s.sync = syncObject
+		case same(top, afterArrayElement):
+			s.sync = syncArray
+		default:
+			s.sync = syncTop
+		}
+		s.stack = s.stack[:n]
+	}
+
+	s.state = recovering
+	s.state(s, c)
+}
+
+// recovering skips input until scanning can resume.
+func recovering(s *Scanner, c byte) Event {
+	// In JSON Lines mode the rest of the line is abandoned.
+	if s.lines {
+		if c == '\n' {
+			s.state = betweenLines
+			s.stack = s.stack[:0]
+			if s.keys != nil {
+				s.keys.reset()
+			}
+			if s.limits != nil {
+				s.limits.reset()
+			}
+			return Space
+		}
+		return None
+	}
+
+	if s.sync == syncTop || c != ',' && c != '}' && c != ']' {
+		return None
+	}
+
+	// Treat mismatched brackets as closing the innermost object or array.
+	if s.sync == syncObject {
+		if c != ',' {
+			c = '}'
+		}
+		s.state = afterObjectValue
+	} else {
+		if c != ',' {
+			c = ']'
+		}
+		s.state = afterArrayElement
+	}
+
+	return s.state(s, c)
+}
+
+// same reports whether a and b are the same state function. Functions can't
+// be compared directly, so their entry points are compared instead.
+func same(a, b func(*Scanner, byte) Event) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
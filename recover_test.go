@@ -0,0 +1,128 @@
+package jo
+
+import (
+	"strings"
+	"testing"
+)
+
+var recoveryTests = []struct {
+	in     string
+	lines  bool
+	events string
+	errs   []string
+}{
+	{
+		`[1, tru, 2]`,
+		false,
+		`ArrayStart, NumberStart, NumberEnd, BoolStart, Error, NumberStart, NumberEnd, ArrayEnd`,
+		[]string{
+			`invalid character ',' after "tru", expected 'e'`,
+		},
+	},
+	{
+		`{"a" 1, "b": [1 2, 3}, "c": true}`,
+		false,
+		`ObjectStart, KeyStart, KeyEnd, Error, KeyStart, KeyEnd, ArrayStart, NumberStart, NumberEnd, Error, NumberStart, Error, ArrayEnd, KeyStart, KeyEnd, BoolStart, BoolEnd, ObjectEnd`,
+		[]string{
+			`invalid character '1' after object key, expected ':'`,
+			`invalid character '2' after array element, expected ',' or ']'`,
+			`invalid character '}' after array element, expected digit, '.', 'e', 'E', ',' or ']'`,
+		},
+	},
+	{
+		`[1,]`,
+		false,
+		`ArrayStart, NumberStart, NumberEnd, Error, ArrayEnd`,
+		[]string{
+			`invalid character ']' in place of value start, expected '{', '[', string, number, true, false or null`,
+		},
+	},
+	{
+		`{"a": x, "b": -}`,
+		false,
+		`ObjectStart, KeyStart, KeyEnd, Error, KeyStart, KeyEnd, NumberStart, Error, ObjectEnd`,
+		[]string{
+			`invalid character 'x' in place of value start, expected '{', '[', string, number, true, false or null`,
+			`invalid character '}' after "-", expected digit`,
+		},
+	},
+	{
+		`{,}`,
+		false,
+		`ObjectStart, Error, Error, ObjectEnd`,
+		[]string{
+			`invalid character ',' in object, expected string or '}'`,
+			`invalid character '}' in place of object key, expected string`,
+		},
+	},
+	{
+		`[[1 2], [3]`,
+		false,
+		`ArrayStart, ArrayStart, NumberStart, NumberEnd, Error, ArrayEnd, ArrayStart, NumberStart, NumberEnd, Error`,
+		[]string{
+			`invalid character '2' after array element, expected ',' or ']'`,
+			`unexpected end of JSON input, expected ',' or ']'`,
+		},
+	},
+	{
+		"{\"a\": 1}\n{\"b\" 2, \"c\": [}\nnull\n\"x\n[]",
+		true,
+		`ObjectStart, KeyStart, KeyEnd, NumberStart, NumberEnd, ObjectEnd, ObjectStart, KeyStart, KeyEnd, Error, NullStart, NullEnd, StringStart, Error, ArrayStart, ArrayEnd`,
+		[]string{
+			`invalid character '2' after object key, expected ':'`,
+			`invalid character '\n' in string literal, expected '"', '\\' or non-control character`,
+		},
+	},
+	{
+		`1 2 3`,
+		false,
+		`NumberStart, NumberEnd, Error`,
+		[]string{
+			`invalid character '2' after top-level value, expected end of input`,
+		},
+	},
+}
+
+func TestRecovery(t *testing.T) {
+	for _, test := range recoveryTests {
+		var s = NewScanner()
+		var out []string
+
+		s.SetLines(test.lines)
+		s.SetRecovery(true)
+
+		for i := 0; i <= len(test.in); i++ {
+			var ev Event
+
+			if i < len(test.in) {
+				ev = s.Scan(test.in[i])
+			} else {
+				ev = s.End()
+			}
+
+			if ev != Error {
+				ev &= ^Space
+			}
+			if ev != None {
+				out = append(out, ev.String())
+			}
+		}
+
+		var errs []string
+		for _, err := range s.Errors() {
+			errs = append(errs, err.Error())
+		}
+
+		if strings.Join(out, ", ") != test.events || strings.Join(errs, "\n") != strings.Join(test.errs, "\n") {
+			t.Errorf("Scanner(%#q) in recovery mode:", test.in)
+			t.Errorf("  got  %s", strings.Join(out, ", "))
+			t.Errorf("  want %s", test.events)
+			for _, err := range errs {
+				t.Errorf("  got  error %q", err)
+			}
+			for _, err := range test.errs {
+				t.Errorf("  want error %q", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package jo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// A RedactRule selects values to be redacted, and determines what they are
+// replaced with.
+type RedactRule struct {
+	// Key selects the values of all members with this key, at any depth.
+	// Path instead selects values by location, given as a JSON Pointer in
+	// which "*" matches any key or index. Exactly one should be set.
+	Key  string
+	Path string
+
+	// Replacement for matching values, as raw JSON. The default is the
+	// string "[REDACTED]".
+	Placeholder []byte
+
+	// Replace values with a string holding the hex-encoded SHA-256 hash of
+	// their raw text, prefixed by "sha256:", instead. This allows values to
+	// be correlated without being revealed.
+	Hash bool
+}
+
+var defaultPlaceholder = []byte(`"[REDACTED]"`)
+
+// Redact copies a single document from src to dst, replacing the values
+// selected by rules. Everything else is copied verbatim. The document is
+// processed as a stream, without ever being held in memory as a whole.
+//
+// Output written before a syntax error is encountered is not retracted.
+func Redact(dst io.Writer, src io.Reader, rules []RedactRule) error {
+	paths := make([][]string, len(rules))
+	for i, r := range rules {
+		if r.Path != "" {
+			p, err := SplitPointer(r.Path)
+			if err != nil {
+				return err
+			}
+			paths[i] = p
+		}
+	}
+
+	var w = bufio.NewWriter(dst)
+	var st stream
+
+	// Rule matching the value being skipped, if any, and the depth at which
+	// the value is found.
+	var rule *RedactRule
+	var depth int
+	var h hash.Hash
+
+	err := st.run(src, func(ev Event, c int) error {
+		if rule != nil {
+			if ev&End == 0 || ev&End == KeyEnd || len(st.stack) != depth {
+				if h != nil && c >= 0 {
+					h.Write([]byte{byte(c)})
+				}
+				return nil
+			}
+
+			// The value has ended.
+			if h != nil {
+				var sum [sha256.Size]byte
+				w.WriteString(`"sha256:`)
+				w.WriteString(hex.EncodeToString(h.Sum(sum[:0])))
+				w.WriteByte('"')
+			} else if rule.Placeholder != nil {
+				w.Write(rule.Placeholder)
+			} else {
+				w.Write(defaultPlaceholder)
+			}
+
+			rule, h = nil, nil
+		}
+
+		if start := ev & Start; start != 0 && start != KeyStart {
+			for i := range rules {
+				if redacts(&st, &rules[i], paths[i]) {
+					rule, depth = &rules[i], len(st.stack)
+					if rule.Hash {
+						h = sha256.New()
+						h.Write([]byte{byte(c)})
+					}
+					return nil
+				}
+			}
+		}
+
+		if c >= 0 {
+			w.WriteByte(byte(c))
+		}
+		return nil
+	})
+
+	if err != nil {
+		w.Flush()
+		return err
+	}
+	return w.Flush()
+}
+
+// redacts reports whether a rule applies to the value at the stream's
+// current location.
+func redacts(st *stream, r *RedactRule, path []string) bool {
+	if r.Path != "" {
+		return st.match(path)
+	}
+
+	n := len(st.stack)
+	return n > 0 && !st.stack[n-1].array && string(st.stack[n-1].key) == r.Key
+}
@@ -0,0 +1,61 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var redactTests = []struct {
+	rules []RedactRule
+	in    string
+	out   string
+}{
+	{
+		[]RedactRule{{Key: "password"}, {Key: "token"}},
+		`{"user": "ann", "password": "hunter2", "auth": {"token": [1, 2], "type": "basic"}}`,
+		`{"user": "ann", "password": "[REDACTED]", "auth": {"token": "[REDACTED]", "type": "basic"}}`,
+	},
+	{
+		[]RedactRule{{Path: "/user/ssn", Placeholder: []byte(`null`)}},
+		"{\n  \"user\": {\"ssn\": \"123-45-6789\", \"name\": \"x\"},\n  \"ssn\": 1\n}\n",
+		"{\n  \"user\": {\"ssn\": null, \"name\": \"x\"},\n  \"ssn\": 1\n}\n",
+	},
+	{
+		[]RedactRule{{Path: "/users/*/email"}},
+		`{"users": [{"email": "a@b"}, {"name": "c"}, {"email": "d@e"}], "email": "f@g"}`,
+		`{"users": [{"email": "[REDACTED]"}, {"name": "c"}, {"email": "[REDACTED]"}], "email": "f@g"}`,
+	},
+	{
+		[]RedactRule{{Path: "/1"}, {Key: "a~b"}},
+		`[1,2.5e3,{"a~b":true}]`,
+		`[1,"[REDACTED]",{"a~b":"[REDACTED]"}]`,
+	},
+	{
+		[]RedactRule{{Key: "k", Hash: true}},
+		`{"k": "secret"}`,
+		`{"k": "sha256:c1980264fc223a890afae83c91bd2d438ef2ad0dae751fdea3660c3e556b1396"}`,
+	},
+	{
+		[]RedactRule{{Key: "n"}},
+		`{"n": 12}`,
+		`{"n": "[REDACTED]"}`,
+	},
+}
+
+func TestRedact(t *testing.T) {
+	for _, test := range redactTests {
+		var buf bytes.Buffer
+
+		if err := Redact(&buf, strings.NewReader(test.in), test.rules); err != nil {
+			t.Errorf("%#q: %s", test.in, err)
+			continue
+		}
+
+		if buf.String() != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %#q", buf.String())
+			t.Errorf("  want %#q", test.out)
+		}
+	}
+}
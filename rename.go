@@ -0,0 +1,120 @@
+package jo
+
+import (
+	"bufio"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenameKeys copies a single document from src to dst, replacing every
+// object key with the result of passing it to rename. Keys which rename
+// leaves unchanged are copied verbatim, escape sequences and all, as is
+// everything else. The document is processed as a stream, without ever
+// being held in memory as a whole.
+//
+// If any paths are given, only the keys of objects found at or below one
+// of them are renamed. Paths are written as for Allow.
+//
+// Output written before a syntax error is encountered is not retracted.
+func RenameKeys(dst io.Writer, src io.Reader, rename func(key string) string, paths ...string) error {
+	scope, err := parsePaths(paths)
+	if err != nil {
+		return err
+	}
+
+	var w = bufio.NewWriter(dst)
+	var st stream
+	var buf []byte
+
+	err = st.run(src, func(ev Event, c int) error {
+		if ev&End == KeyEnd {
+			n := len(st.stack)
+			key := string(st.stack[n-1].key)
+
+			if k := rename(key); k != key && inScope(&st, scope) {
+				buf = appendQuote(buf[:0], []byte(k))
+				w.Write(buf)
+			} else {
+				w.Write(st.key)
+			}
+		}
+
+		if c >= 0 && !st.inKey {
+			w.WriteByte(byte(c))
+		}
+		return nil
+	})
+
+	if err != nil {
+		w.Flush()
+		return err
+	}
+	return w.Flush()
+}
+
+// inScope reports whether the object enclosing the stream's current
+// location is found at or below one of the paths. An empty set of paths
+// matches everything.
+func inScope(st *stream, paths [][]string) bool {
+	for _, path := range paths {
+		if len(path) < len(st.stack) && st.prefix(path) {
+			return true
+		}
+	}
+	return len(paths) == 0
+}
+
+// MapKeys returns a function for use with RenameKeys which renames keys
+// according to a table. Keys missing from the table are left alone.
+func MapKeys(m map[string]string) func(string) string {
+	return func(key string) string {
+		if k, ok := m[key]; ok {
+			return k
+		}
+		return key
+	}
+}
+
+// SnakeToCamel converts a key from snake_case to camelCase, turning
+// "user_id" into "userId". Leading and trailing underscores are kept.
+func SnakeToCamel(key string) string {
+	var buf []byte
+	var upper bool
+
+	for i, r := range key {
+		switch {
+		case r == '_' && len(buf) > 0 && i+1 < len(key) && key[i+1] != '_':
+			upper = true
+			continue
+		case upper:
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+
+	return string(buf)
+}
+
+// CamelToSnake converts a key from camelCase to snake_case, turning
+// "userId" into "user_id". Runs of capitals are treated as single words,
+// so that "HTTPServer" becomes "http_server".
+func CamelToSnake(key string) string {
+	var buf []byte
+	var rs = []rune(key)
+
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			next := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && next {
+				buf = append(buf, '_')
+			}
+		}
+		buf = utf8.AppendRune(buf, unicode.ToLower(r))
+	}
+
+	return string(buf)
+}
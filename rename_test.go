@@ -0,0 +1,87 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var renameKeysTests = []struct {
+	rename func(string) string
+	paths  []string
+	in     string
+	out    string
+}{
+	{
+		SnakeToCamel,
+		nil,
+		`{"user_id": 1, "first_name": "Ann", "tags": [{"tag_name": "x"}], "plain": "snake_case"}`,
+		`{"userId": 1, "firstName": "Ann", "tags": [{"tagName": "x"}], "plain": "snake_case"}`,
+	},
+	{
+		CamelToSnake,
+		nil,
+		"{\n  \"userId\" : {\"HTTPServer\": true},\n  \"\\u0061\": null\n}",
+		"{\n  \"user_id\" : {\"http_server\": true},\n  \"\\u0061\": null\n}",
+	},
+	{
+		MapKeys(map[string]string{"a": "b", "q\"": "r\n"}),
+		nil,
+		`[{"a": {"a": 1}}, {"q\"": 2, "c": 3}]`,
+		`[{"b": {"b": 1}}, {"r\n": 2, "c": 3}]`,
+	},
+	{
+		SnakeToCamel,
+		[]string{"data", "/list/*"},
+		`{"top_level": {"data": 1}, "data": {"some_key": {"deep_key": 2}}, "list": [{"a_b": 3}]}`,
+		`{"top_level": {"data": 1}, "data": {"someKey": {"deepKey": 2}}, "list": [{"aB": 3}]}`,
+	},
+}
+
+func TestRenameKeys(t *testing.T) {
+	for _, test := range renameKeysTests {
+		var buf bytes.Buffer
+
+		if err := RenameKeys(&buf, strings.NewReader(test.in), test.rename, test.paths...); err != nil {
+			t.Errorf("%#q: %s", test.in, err)
+			continue
+		}
+
+		if buf.String() != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %#q", buf.String())
+			t.Errorf("  want %#q", test.out)
+		}
+	}
+}
+
+var caseTests = []struct {
+	snake, camel string
+}{
+	{"user_id", "userId"},
+	{"a_b_c", "aBC"},
+	{"_private", "_private"},
+	{"trailing_", "trailing_"},
+	{"name", "name"},
+	{"größe_änderung", "größeÄnderung"},
+}
+
+func TestCase(t *testing.T) {
+	for _, test := range caseTests {
+		if got := SnakeToCamel(test.snake); got != test.camel {
+			t.Errorf("SnakeToCamel(%q) = %q, want %q", test.snake, got, test.camel)
+		}
+	}
+
+	for _, test := range []struct{ in, out string }{
+		{"userId", "user_id"},
+		{"HTTPServer", "http_server"},
+		{"userID", "user_id"},
+		{"version2Name", "version2_name"},
+		{"name", "name"},
+	} {
+		if got := CamelToSnake(test.in); got != test.out {
+			t.Errorf("CamelToSnake(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+}
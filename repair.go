@@ -0,0 +1,323 @@
+package jo
+
+import (
+	"fmt"
+	"io"
+)
+
+// A Fix describes a change made by Repair.
+type Fix struct {
+	// Offset of the offending input.
+	Offset int64
+
+	Message string
+}
+
+func (f Fix) String() string {
+	return fmt.Sprintf("offset %d: %s", f.Offset, f.Message)
+}
+
+// Replacements for non-standard literals, as written by Python or
+// JavaScript.
+var literals = map[string]string{
+	"True":      "true",
+	"False":     "false",
+	"None":      "null",
+	"NaN":       "null",
+	"Infinity":  "null",
+	"-Infinity": "null",
+	"undefined": "null",
+}
+
+// Repair reads a single, possibly malformed, document from src, and writes
+// a well-formed version of it to dst. It undoes the most common departures
+// from the JSON grammar:
+//
+//   - strings and keys in single quotes
+//   - unquoted keys, and bare words in place of string values
+//   - True, False and None, as well as NaN, Infinity and undefined, which
+//     are replaced with null
+//   - trailing and duplicate commas
+//   - missing commas between values, and missing colons after keys
+//   - control characters, such as newlines, in strings
+//   - backslashes not starting a valid escape sequence
+//   - leading zeros in numbers
+//   - truncated documents, which are completed as by CloseOpen
+//
+// Each change is described by a Fix. Input which can't be repaired results
+// in a *SyntaxError, in which case nothing is written to dst.
+func Repair(dst io.Writer, src io.Reader) (fixes []Fix, err error) {
+	in, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &repairer{s: NewScanner(), in: in}
+	if err := r.run(); err != nil {
+		return r.fixes, err
+	}
+
+	_, err = dst.Write(r.out)
+	return r.fixes, err
+}
+
+// A repairer feeds a Scanner a repaired version of its input. Before each
+// byte is fed to the Scanner, it checks whether the Scanner would accept it,
+// and if not, decides on a fix based on the Scanner's state.
+type repairer struct {
+	s *Scanner
+
+	in  []byte
+	i   int
+	out []byte
+
+	// Length of the output up to its last byte other than whitespace.
+	mark int
+
+	// Whether the string being scanned was opened with a single quote.
+	single bool
+
+	fixes []Fix
+}
+
+func (r *repairer) run() error {
+	for r.i < len(r.in) {
+		c := r.in[r.i]
+		st := r.s.state
+
+		switch {
+		case same(st, afterQuote):
+			if r.string(c) {
+				continue
+			}
+		case same(st, beforeValue), same(st, beforeFirstArrayElement),
+			same(st, beforeFirstObjectKey), same(st, afterObjectComma):
+			if r.value(c) {
+				continue
+			}
+		case same(st, afterMinus):
+			if r.zeros(c) {
+				continue
+			}
+		}
+
+		set := r.s.Allowed()
+
+		if set[c] {
+			if c == ',' && r.dangling() {
+				r.fix("removed trailing comma")
+				r.i++
+				continue
+			}
+			r.emit(c)
+			r.i++
+			continue
+		}
+
+		switch {
+		case c == ',':
+			r.fix("removed extra comma")
+			r.i++
+		case set[','] && startsValue(c):
+			r.fix("inserted missing comma")
+			r.insert(',')
+		case set[':'] && startsValue(c):
+			r.fix("inserted missing colon")
+			r.insert(':')
+		case same(st, afterEsc):
+			r.fixes = append(r.fixes, Fix{int64(r.i - 1), "escaped stray backslash"})
+			r.emit('\\')
+		default:
+			r.s.Scan(c)
+			err := r.s.LastError()
+			if se, ok := err.(*SyntaxError); ok {
+				se.Offset = int64(r.i)
+			}
+			return err
+		}
+	}
+
+	if r.s.CanEnd() {
+		return nil
+	}
+
+	if doc := CloseOpen(r.out); doc != nil {
+		r.fix("completed truncated document")
+		r.out = doc
+		return nil
+	}
+
+	r.s.End()
+	err := r.s.LastError()
+	if se, ok := err.(*SyntaxError); ok {
+		se.Offset = int64(len(r.in))
+	}
+	return err
+}
+
+// string handles the byte c within a string, returning true if it needed
+// fixing.
+func (r *repairer) string(c byte) bool {
+	switch {
+	case c < 0x20:
+		r.fix("escaped control character in string")
+		switch c {
+		case '\n':
+			r.emit('\\', 'n')
+		case '\r':
+			r.emit('\\', 'r')
+		case '\t':
+			r.emit('\\', 't')
+		default:
+			r.emit('\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+		}
+	case c == '\'' && r.single:
+		r.emit('"')
+		r.single = false
+	case c == '"' && r.single:
+		r.emit('\\', '"')
+	case c == '\\' && r.peek(1) == '\'':
+		if !r.single {
+			r.fix("removed invalid escape sequence")
+		}
+		r.emit('\'')
+		r.i++
+	default:
+		return false
+	}
+
+	r.i++
+	return true
+}
+
+// value handles c in place of a value or key, returning true if it needed
+// fixing.
+func (r *repairer) value(c byte) bool {
+	st := r.s.state
+	key := same(st, beforeFirstObjectKey) || same(st, afterObjectComma)
+
+	if c == '\'' {
+		r.fix("replaced single quotes with double quotes")
+		r.emit('"')
+		r.single = true
+		r.i++
+		return true
+	}
+
+	if !key && r.zeros(c) {
+		return true
+	}
+
+	if !isWordStart(c) && !(key && table[c]&isDigit != 0) && !(c == '-' && r.peek(1) == 'I') {
+		return false
+	}
+
+	n := 1
+	for r.i+n < len(r.in) && isWordByte(r.in[r.i+n]) {
+		n++
+	}
+	word := string(r.in[r.i : r.i+n])
+
+	switch lit, ok := literals[word]; {
+	case key:
+		r.fix("quoted key")
+		r.emit(appendQuote(nil, []byte(word))...)
+	case word == "true", word == "false", word == "null":
+		r.emit([]byte(word)...)
+	case ok:
+		r.fix(fmt.Sprintf("replaced %s with %s", word, lit))
+		r.emit([]byte(lit)...)
+	case c == '-':
+		return false
+	default:
+		r.fix("quoted bare word")
+		r.emit(appendQuote(nil, []byte(word))...)
+	}
+
+	r.i += n
+	return true
+}
+
+// zeros drops leading zeros from the integer part of a number starting with
+// c, returning true if there were any. Otherwise the zero would be taken
+// for a number of its own, and the digits following it for another value.
+func (r *repairer) zeros(c byte) bool {
+	n := 0
+	for c == '0' && table[r.peek(n+1)]&isDigit != 0 {
+		n++
+		c = r.peek(n)
+	}
+	if n == 0 {
+		return false
+	}
+
+	if n == 1 {
+		r.fix("removed leading zero")
+	} else {
+		r.fix("removed leading zeros")
+	}
+	r.i += n
+	return true
+}
+
+// dangling reports whether the comma at the current position is followed
+// by another comma, the end of an object or array, or the end of input.
+func (r *repairer) dangling() bool {
+	for j := r.i + 1; j < len(r.in); j++ {
+		switch c := r.in[j]; {
+		case table[c]&isSpace != 0:
+			continue
+		case c == ',', c == '}', c == ']':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// emit feeds repaired input to the Scanner, and appends it to the output.
+func (r *repairer) emit(b ...byte) {
+	for _, c := range b {
+		r.s.Scan(c)
+		r.out = append(r.out, c)
+		if table[c]&isSpace == 0 || same(r.s.state, afterQuote) {
+			r.mark = len(r.out)
+		}
+	}
+}
+
+// insert feeds a separator to the Scanner, but inserts it into the output
+// right after the preceding value, ahead of any whitespace.
+func (r *repairer) insert(c byte) {
+	r.s.Scan(c)
+	r.out = append(r.out, 0)
+	copy(r.out[r.mark+1:], r.out[r.mark:])
+	r.out[r.mark] = c
+	r.mark++
+}
+
+func (r *repairer) fix(msg string) {
+	r.fixes = append(r.fixes, Fix{int64(r.i), msg})
+}
+
+// peek returns the byte n bytes ahead, or 0 at the end of input.
+func (r *repairer) peek(n int) byte {
+	if r.i+n < len(r.in) {
+		return r.in[r.i+n]
+	}
+	return 0
+}
+
+func startsValue(c byte) bool {
+	return c == '"' || c == '\'' || c == '{' || c == '[' || c == '-' ||
+		table[c]&isDigit != 0 || isWordStart(c)
+}
+
+func isWordStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' || c == '$'
+}
+
+func isWordByte(c byte) bool {
+	return isWordStart(c) || table[c]&isDigit != 0 || c == '-' || c == '.'
+}
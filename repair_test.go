@@ -0,0 +1,173 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var repairTests = []struct {
+	in    string
+	out   string
+	fixes []string
+	err   string
+}{
+	{
+		`{"a": [1, 2], "b": null}`,
+		`{"a": [1, 2], "b": null}`,
+		nil,
+		``,
+	},
+	{
+		`{'a': 'it\'s "x"', b: True, c_2: None, 3: False}`,
+		`{"a": "it's \"x\"", "b": true, "c_2": null, "3": false}`,
+		[]string{
+			`offset 1: replaced single quotes with double quotes`,
+			`offset 6: replaced single quotes with double quotes`,
+			`offset 19: quoted key`,
+			`offset 22: replaced True with true`,
+			`offset 28: quoted key`,
+			`offset 33: replaced None with null`,
+			`offset 39: quoted key`,
+			`offset 42: replaced False with false`,
+		},
+		``,
+	},
+	{
+		`[1, 2, 3,]`,
+		`[1, 2, 3]`,
+		[]string{`offset 8: removed trailing comma`},
+		``,
+	},
+	{
+		`{"a": 1,, "b": 2 , }`,
+		`{"a": 1, "b": 2  }`,
+		[]string{
+			`offset 7: removed trailing comma`,
+			`offset 17: removed trailing comma`,
+		},
+		``,
+	},
+	{
+		`[,1]`,
+		`[1]`,
+		[]string{`offset 1: removed extra comma`},
+		``,
+	},
+	{
+		"[1 2\n\"a\" {\"b\" 3 \"c\": []} [true]]",
+		"[1, 2,\n\"a\", {\"b\": 3, \"c\": []}, [true]]",
+		[]string{
+			`offset 3: inserted missing comma`,
+			`offset 5: inserted missing comma`,
+			`offset 9: inserted missing comma`,
+			`offset 14: inserted missing colon`,
+			`offset 16: inserted missing comma`,
+			`offset 25: inserted missing comma`,
+		},
+		``,
+	},
+	{
+		"{\"text\": \"line one\nline two\ttab\x01\"}",
+		`{"text": "line one\nline two\ttab\u0001"}`,
+		[]string{
+			`offset 18: escaped control character in string`,
+			`offset 27: escaped control character in string`,
+			`offset 31: escaped control character in string`,
+		},
+		``,
+	},
+	{
+		`["C:\path", "\'"]`,
+		`["C:\\path", "'"]`,
+		[]string{
+			`offset 4: escaped stray backslash`,
+			`offset 13: removed invalid escape sequence`,
+		},
+		``,
+	},
+	{
+		`[NaN, -Infinity, undefined, nope]`,
+		`[null, null, null, "nope"]`,
+		[]string{
+			`offset 1: replaced NaN with null`,
+			`offset 6: replaced -Infinity with null`,
+			`offset 17: replaced undefined with null`,
+			`offset 28: quoted bare word`,
+		},
+		``,
+	},
+	{
+		`{"a": 01, "b": [-007, 0, 0.5, 00]}`,
+		`{"a": 1, "b": [-7, 0, 0.5, 0]}`,
+		[]string{
+			`offset 6: removed leading zero`,
+			`offset 17: removed leading zeros`,
+			`offset 30: removed leading zero`,
+		},
+		``,
+	},
+	{
+		`{"a": [1, {'b': 'c`,
+		`{"a": [1, {"b": "c"}]}`,
+		[]string{
+			`offset 11: replaced single quotes with double quotes`,
+			`offset 16: replaced single quotes with double quotes`,
+			`offset 18: completed truncated document`,
+		},
+		``,
+	},
+	{
+		`{"a": 1}}`,
+		``,
+		nil,
+		`invalid character '}' after top-level value, expected end of input`,
+	},
+	{
+		`[1, -]`,
+		``,
+		nil,
+		`invalid character ']' after "-", expected digit`,
+	},
+	{
+		``,
+		``,
+		nil,
+		`unexpected end of JSON input, expected '{', '[', string, number, true, false or null`,
+	},
+}
+
+func TestRepair(t *testing.T) {
+	for _, test := range repairTests {
+		var buf bytes.Buffer
+		var msg string
+
+		fixes, err := Repair(&buf, strings.NewReader(test.in))
+		if err != nil {
+			msg = err.Error()
+		}
+
+		var got []string
+		for _, f := range fixes {
+			got = append(got, f.String())
+		}
+
+		if msg != test.err || buf.String() != test.out || strings.Join(got, "\n") != strings.Join(test.fixes, "\n") {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %#q, %q", buf.String(), msg)
+			t.Errorf("  want %#q, %q", test.out, test.err)
+			for _, f := range got {
+				t.Errorf("  got fix  %s", f)
+			}
+			for _, f := range test.fixes {
+				t.Errorf("  want fix %s", f)
+			}
+		}
+
+		if err == nil {
+			if _, err := decodeBytes(buf.Bytes()); err != nil {
+				t.Errorf("%#q: output %#q is invalid: %v", test.in, buf.String(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package jo
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSON Schema type flags.
+const (
+	typeNull = 1 << iota
+	typeBoolean
+	typeObject
+	typeArray
+	typeNumber
+	typeInteger
+	typeString
+)
+
+var typeNames = map[string]int{
+	"null":    typeNull,
+	"boolean": typeBoolean,
+	"object":  typeObject,
+	"array":   typeArray,
+	"number":  typeNumber,
+	"integer": typeInteger,
+	"string":  typeString,
+}
+
+// A Schema is a compiled JSON Schema, capable of validating documents as
+// they are being scanned.
+//
+// Only a subset of the draft 2020-12 vocabulary is supported: the type,
+// enum, const, properties, required, additionalProperties, minProperties,
+// maxProperties, items, minItems, maxItems, minLength, maxLength, pattern,
+// minimum, maximum, exclusiveMinimum and exclusiveMaximum keywords.
+// Annotations such as title and description are ignored.
+type Schema struct {
+	// Set for the boolean schema false, which matches nothing.
+	never bool
+
+	types int
+	enum  []interface{}
+
+	properties map[string]*Schema
+	required   []string
+	additional *Schema
+
+	items *Schema
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+
+	minLength, maxLength         int
+	minItems, maxItems           int
+	minProperties, maxProperties int
+
+	pattern *regexp.Regexp
+}
+
+// A SchemaError describes a problem with a schema being compiled.
+type SchemaError struct {
+	Pointer string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("invalid schema at %q: %s", e.Pointer, e.Message)
+}
+
+// CompileSchema parses and compiles a JSON Schema.
+func CompileSchema(src []byte) (*Schema, error) {
+	v, err := decodeBytes(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return compileSchema(v, "")
+}
+
+func compileSchema(v interface{}, ptr string) (*Schema, error) {
+	sc := &Schema{
+		minLength: -1, maxLength: -1,
+		minItems: -1, maxItems: -1,
+		minProperties: -1, maxProperties: -1,
+	}
+
+	switch v := v.(type) {
+	case bool:
+		sc.never = !v
+		return sc, nil
+	case object:
+		for _, m := range v {
+			if err := sc.compileKeyword(m.key, m.val, ptr+"/"+string(appendPointerToken(nil, []byte(m.key)))); err != nil {
+				return nil, err
+			}
+		}
+		return sc, nil
+	}
+
+	return nil, &SchemaError{ptr, "schema must be an object or a boolean"}
+}
+
+func (sc *Schema) compileKeyword(key string, v interface{}, ptr string) error {
+	var err error
+
+	switch key {
+	case "type":
+		switch v := v.(type) {
+		case string:
+			sc.types, err = typeFlag(v, ptr)
+		case []interface{}:
+			for _, t := range v {
+				s, _ := t.(string)
+				f, err := typeFlag(s, ptr)
+				if err != nil {
+					return err
+				}
+				sc.types |= f
+			}
+		default:
+			err = &SchemaError{ptr, "must be a string or an array"}
+		}
+
+	case "enum":
+		if a, ok := v.([]interface{}); ok {
+			sc.enum = a
+		} else {
+			err = &SchemaError{ptr, "must be an array"}
+		}
+
+	case "const":
+		sc.enum = []interface{}{v}
+
+	case "properties":
+		obj, ok := v.(object)
+		if !ok {
+			return &SchemaError{ptr, "must be an object"}
+		}
+		sc.properties = make(map[string]*Schema, len(obj))
+		for _, m := range obj {
+			sub, err := compileSchema(m.val, ptr+"/"+string(appendPointerToken(nil, []byte(m.key))))
+			if err != nil {
+				return err
+			}
+			sc.properties[m.key] = sub
+		}
+
+	case "required":
+		a, ok := v.([]interface{})
+		if !ok {
+			return &SchemaError{ptr, "must be an array"}
+		}
+		for _, k := range a {
+			s, ok := k.(string)
+			if !ok {
+				return &SchemaError{ptr, "must be an array of strings"}
+			}
+			sc.required = append(sc.required, s)
+		}
+
+	case "additionalProperties":
+		sc.additional, err = compileSchema(v, ptr)
+
+	case "items":
+		sc.items, err = compileSchema(v, ptr)
+
+	case "minimum":
+		sc.minimum, err = floatKeyword(v, ptr)
+	case "maximum":
+		sc.maximum, err = floatKeyword(v, ptr)
+	case "exclusiveMinimum":
+		sc.exclusiveMinimum, err = floatKeyword(v, ptr)
+	case "exclusiveMaximum":
+		sc.exclusiveMaximum, err = floatKeyword(v, ptr)
+
+	case "minLength":
+		sc.minLength, err = intKeyword(v, ptr)
+	case "maxLength":
+		sc.maxLength, err = intKeyword(v, ptr)
+	case "minItems":
+		sc.minItems, err = intKeyword(v, ptr)
+	case "maxItems":
+		sc.maxItems, err = intKeyword(v, ptr)
+	case "minProperties":
+		sc.minProperties, err = intKeyword(v, ptr)
+	case "maxProperties":
+		sc.maxProperties, err = intKeyword(v, ptr)
+
+	case "pattern":
+		s, ok := v.(string)
+		if !ok {
+			return &SchemaError{ptr, "must be a string"}
+		}
+		if sc.pattern, err = regexp.Compile(s); err != nil {
+			err = &SchemaError{ptr, err.Error()}
+		}
+
+	case "$ref", "$dynamicRef", "allOf", "anyOf", "oneOf", "not",
+		"if", "then", "else", "prefixItems", "patternProperties",
+		"dependentRequired", "dependentSchemas", "unevaluatedItems",
+		"unevaluatedProperties", "contains", "propertyNames":
+		err = &SchemaError{ptr, "unsupported keyword"}
+	}
+
+	return err
+}
+
+func typeFlag(name, ptr string) (int, error) {
+	if f, ok := typeNames[name]; ok {
+		return f, nil
+	}
+	return 0, &SchemaError{ptr, fmt.Sprintf("unknown type %q", name)}
+}
+
+func floatKeyword(v interface{}, ptr string) (*float64, error) {
+	if n, ok := v.(number); ok {
+		f := n.float()
+		return &f, nil
+	}
+	return nil, &SchemaError{ptr, "must be a number"}
+}
+
+func intKeyword(v interface{}, ptr string) (int, error) {
+	if n, ok := v.(number); ok {
+		if f := n.float(); f >= 0 && f == math.Trunc(f) {
+			return int(f), nil
+		}
+	}
+	return 0, &SchemaError{ptr, "must be a non-negative integer"}
+}
+
+// A Violation describes a part of a document which does not conform to a
+// schema, or to the I-JSON profile in strict mode.
+type Violation struct {
+	// Location of the offending value.
+	Pointer string
+	Offset  int64
+
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%q (offset %d): %s", v.Pointer, v.Offset, v.Message)
+}
+
+// A validation is the state of a value being validated.
+type validation struct {
+	schema *Schema
+	ptr    string
+	off    int64
+
+	// Number of elements or members, and the keys seen so far.
+	count int
+	keys  map[string]bool
+
+	// Builds the complete value when needed for enum comparisons.
+	b *builder
+}
+
+// Validate scans a single document from r and checks it against the
+// schema. Syntax and I/O errors are returned as err, in which case the
+// list of violations may be incomplete.
+func (sc *Schema) Validate(r io.Reader) ([]*Violation, error) {
+	var t = newTokenizer(r)
+	var stack []*validation
+	var out []*Violation
+
+	report := func(ptr string, off int64, format string, args ...interface{}) {
+		out = append(out, &Violation{ptr, off, fmt.Sprintf(format, args...)})
+	}
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return out, err
+		}
+
+		// Feed the token to any values being built for enum comparisons.
+		for _, v := range stack {
+			if v.b != nil && v.b.add(tok) {
+				v.check(report)
+			}
+		}
+
+		if tok.kind == ObjectEnd || tok.kind == ArrayEnd {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			v.finish(tok.kind, report)
+			continue
+		}
+		if !isValue(tok.kind) {
+			continue
+		}
+
+		// Figure out which schema applies to the value.
+		var v = &validation{schema: sc, ptr: t.pointer(false), off: tok.off}
+
+		if n := len(stack); n > 0 {
+			parent := stack[n-1]
+			parent.count++
+			v.schema = nil
+
+			if parent.schema != nil && t.stack[n-1].array {
+				v.schema = parent.schema.items
+			} else if parent.schema != nil {
+				key := string(t.stack[n-1].key)
+
+				if parent.keys != nil {
+					parent.keys[key] = true
+				}
+
+				if sub, ok := parent.schema.properties[key]; ok {
+					v.schema = sub
+				} else if add := parent.schema.additional; add != nil && add.never {
+					report(v.ptr, v.off, "property %q not allowed", key)
+				} else {
+					v.schema = add
+				}
+			}
+		}
+
+		v.start(tok, report)
+
+		if tok.kind == ObjectStart || tok.kind == ArrayStart {
+			if v.schema != nil && v.schema.enum != nil {
+				v.b = new(builder)
+				v.b.add(tok)
+			}
+			stack = append(stack, v)
+		} else if v.schema != nil && v.schema.enum != nil {
+			v.b = new(builder)
+			v.b.add(tok)
+			v.check(report)
+		}
+	}
+}
+
+// start checks the constraints which can be verified as soon as a value
+// begins.
+func (v *validation) start(tok token, report func(string, int64, string, ...interface{})) {
+	sc := v.schema
+	if sc == nil {
+		return
+	}
+	if sc.never {
+		report(v.ptr, v.off, "value not allowed")
+		v.schema = nil
+		return
+	}
+
+	flag := typeOf(tok)
+
+	// The "number" type includes all integers.
+	types := sc.types
+	if types&typeNumber != 0 {
+		types |= typeInteger
+	}
+
+	if types != 0 && types&flag == 0 {
+		report(v.ptr, v.off, "expected %s, found %s", typeList(sc.types), typeList(flag&^typeInteger))
+	}
+
+	switch tok.kind {
+	case ObjectStart:
+		if sc.required != nil {
+			v.keys = make(map[string]bool)
+		}
+	case StringEnd:
+		s := unquote(nil, tok.text)
+		if n := utf8.RuneCount(s); sc.minLength >= 0 && n < sc.minLength {
+			report(v.ptr, v.off, "string shorter than %d characters", sc.minLength)
+		} else if sc.maxLength >= 0 && n > sc.maxLength {
+			report(v.ptr, v.off, "string longer than %d characters", sc.maxLength)
+		}
+		if sc.pattern != nil && !sc.pattern.Match(s) {
+			report(v.ptr, v.off, "string does not match pattern %q", sc.pattern)
+		}
+	case NumberEnd:
+		f, _ := strconv.ParseFloat(string(tok.text), 64)
+		if sc.minimum != nil && f < *sc.minimum {
+			report(v.ptr, v.off, "number less than %v", *sc.minimum)
+		}
+		if sc.maximum != nil && f > *sc.maximum {
+			report(v.ptr, v.off, "number greater than %v", *sc.maximum)
+		}
+		if sc.exclusiveMinimum != nil && f <= *sc.exclusiveMinimum {
+			report(v.ptr, v.off, "number not greater than %v", *sc.exclusiveMinimum)
+		}
+		if sc.exclusiveMaximum != nil && f >= *sc.exclusiveMaximum {
+			report(v.ptr, v.off, "number not less than %v", *sc.exclusiveMaximum)
+		}
+	}
+}
+
+// typeOf returns the type flags describing the value starting with tok.
+func typeOf(tok token) int {
+	switch tok.kind {
+	case ObjectStart:
+		return typeObject
+	case ArrayStart:
+		return typeArray
+	case StringEnd:
+		return typeString
+	case NumberEnd:
+		if f := number(tok.text).float(); f == math.Trunc(f) {
+			return typeNumber | typeInteger
+		}
+		return typeNumber
+	case BoolEnd:
+		return typeBoolean
+	}
+	return typeNull
+}
+
+// finish checks the constraints which can only be verified once an object
+// or array has ended.
+func (v *validation) finish(kind Event, report func(string, int64, string, ...interface{})) {
+	sc := v.schema
+	if sc == nil {
+		return
+	}
+
+	if kind == ArrayEnd {
+		if sc.minItems >= 0 && v.count < sc.minItems {
+			report(v.ptr, v.off, "array has fewer than %d items", sc.minItems)
+		} else if sc.maxItems >= 0 && v.count > sc.maxItems {
+			report(v.ptr, v.off, "array has more than %d items", sc.maxItems)
+		}
+		return
+	}
+
+	for _, key := range sc.required {
+		if !v.keys[key] {
+			report(v.ptr, v.off, "missing required property %q", key)
+		}
+	}
+	if sc.minProperties >= 0 && v.count < sc.minProperties {
+		report(v.ptr, v.off, "object has fewer than %d properties", sc.minProperties)
+	} else if sc.maxProperties >= 0 && v.count > sc.maxProperties {
+		report(v.ptr, v.off, "object has more than %d properties", sc.maxProperties)
+	}
+}
+
+// check compares a completely built value against the schema's enum.
+func (v *validation) check(report func(string, int64, string, ...interface{})) {
+	for _, e := range v.schema.enum {
+		if equal(v.b.val, e) {
+			return
+		}
+	}
+
+	if len(v.schema.enum) == 1 {
+		report(v.ptr, v.off, "value does not match const")
+	} else {
+		report(v.ptr, v.off, "value not in enum")
+	}
+}
+
+// typeList formats a set of type flags for use in messages.
+func typeList(flags int) string {
+	var names []string
+
+	for name, f := range typeNames {
+		if flags&f != 0 {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, " or ")
+}
@@ -0,0 +1,125 @@
+package jo
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"score": {"type": ["number", "null"], "exclusiveMaximum": 100},
+		"tags": {"type": "array", "items": {"enum": ["a", "b", {"c": [1]}]}, "maxItems": 3},
+		"meta": {"type": "object", "additionalProperties": {"type": "boolean"}, "minProperties": 1}
+	},
+	"required": ["id", "name"],
+	"additionalProperties": false
+}`
+
+var validateTests = []struct {
+	in  string
+	out []string
+}{
+	{
+		`{"id": 1, "name": "ann", "score": null, "tags": ["a", {"c": [1.0]}], "meta": {"x": true}}`,
+		nil,
+	},
+	{
+		`{"id": 2.0, "name": "bob", "score": 99.5}`,
+		nil,
+	},
+	{
+		`[]`,
+		[]string{
+			`"" (offset 0): expected object, found array`,
+		},
+	},
+	{
+		`{"id": 0.5, "name": "Ann", "extra": 1}`,
+		[]string{
+			`"/id" (offset 7): expected integer, found number`,
+			`"/id" (offset 7): number less than 1`,
+			`"/name" (offset 20): string does not match pattern "^[a-z]+$"`,
+			`"/extra" (offset 36): property "extra" not allowed`,
+		},
+	},
+	{
+		`{"name": "", "score": 100, "tags": ["a", "c", {"c": [2]}, "b"], "meta": {}}`,
+		[]string{
+			`"/name" (offset 9): string shorter than 1 characters`,
+			`"/name" (offset 9): string does not match pattern "^[a-z]+$"`,
+			`"/score" (offset 22): number not less than 100`,
+			`"/tags/1" (offset 41): value not in enum`,
+			`"/tags/2" (offset 46): value not in enum`,
+			`"/tags" (offset 35): array has more than 3 items`,
+			`"/meta" (offset 72): object has fewer than 1 properties`,
+			`"" (offset 0): missing required property "id"`,
+		},
+	},
+	{
+		`{"id": 1, "name": "x", "meta": {"a": "yes"}}`,
+		[]string{
+			`"/meta/a" (offset 37): expected boolean, found string`,
+		},
+	},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	sc, err := CompileSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("CompileSchema returned %q", err)
+	}
+
+	for _, test := range validateTests {
+		vs, err := sc.Validate(strings.NewReader(test.in))
+		if err != nil {
+			t.Errorf("Schema.Validate(%#q) returned %q", test.in, err)
+			continue
+		}
+
+		var out []string
+		for _, v := range vs {
+			out = append(out, v.Error())
+		}
+
+		if strings.Join(out, "\n") != strings.Join(test.out, "\n") {
+			t.Errorf("Schema.Validate(%#q):", test.in)
+			for _, s := range out {
+				t.Errorf("  got  %s", s)
+			}
+			for _, s := range test.out {
+				t.Errorf("  want %s", s)
+			}
+		}
+	}
+}
+
+var compileSchemaTests = []struct {
+	in  string
+	err string
+}{
+	{`true`, ``},
+	{`{"type": "strin"}`, `invalid schema at "/type": unknown type "strin"`},
+	{`{"properties": {"a": 1}}`, `invalid schema at "/properties/a": schema must be an object or a boolean`},
+	{`{"minLength": -1}`, `invalid schema at "/minLength": must be a non-negative integer`},
+	{`{"anyOf": []}`, `invalid schema at "/anyOf": unsupported keyword`},
+	{`{"pattern": "("}`, "invalid schema at \"/pattern\": error parsing regexp: missing closing ): `(`"},
+}
+
+func TestCompileSchema(t *testing.T) {
+	for _, test := range compileSchemaTests {
+		var msg string
+		if _, err := CompileSchema([]byte(test.in)); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err {
+			t.Errorf("CompileSchema(%#q):", test.in)
+			t.Errorf("  got  %q", msg)
+			t.Errorf("  want %q", test.err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package jo
+
+import (
+	"io"
+	"sort"
+)
+
+// A Shape describes the structure shared by a set of JSON values, in the
+// manner of a JSON Schema. The zero value describes the empty set.
+type Shape struct {
+	// Values counted by type.
+	Kinds Kinds
+
+	// Shapes of object members, by key.
+	Properties map[string]*Shape
+
+	// Shape of array elements, or nil if no elements have been seen.
+	Items *Shape
+}
+
+// Add scans a single document from r and merges its structure into the
+// Shape.
+func (sh *Shape) Add(r io.Reader) error {
+	return sh.add(newTokenizer(r))
+}
+
+// AddLines scans a stream of newline-separated documents (JSON Lines)
+// from r and merges their structure into the Shape.
+func (sh *Shape) AddLines(r io.Reader) error {
+	t := newTokenizer(r)
+	t.s.SetLines(true)
+	return sh.add(t)
+}
+
+func (sh *Shape) add(t *tokenizer) error {
+	var stack []*Shape
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if tok.kind == ObjectEnd || tok.kind == ArrayEnd {
+			stack = stack[:len(stack)-1]
+		}
+		if !isValue(tok.kind) {
+			continue
+		}
+
+		node := sh
+
+		if n := len(stack); n > 0 {
+			if parent := stack[n-1]; t.stack[n-1].array {
+				if parent.Items == nil {
+					parent.Items = new(Shape)
+				}
+				node = parent.Items
+			} else {
+				if parent.Properties == nil {
+					parent.Properties = make(map[string]*Shape)
+				}
+				key := t.stack[n-1].key
+				if node = parent.Properties[string(key)]; node == nil {
+					node = new(Shape)
+					parent.Properties[string(key)] = node
+				}
+			}
+		}
+
+		node.Kinds.add(tok)
+
+		if tok.kind == ObjectStart || tok.kind == ArrayStart {
+			stack = append(stack, node)
+		}
+	}
+}
+
+// Required returns the sorted keys of all properties present in every
+// object described by the Shape.
+func (sh *Shape) Required() []string {
+	var keys []string
+
+	for key, prop := range sh.Properties {
+		if prop.Kinds.Total() >= sh.Kinds.Object {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Types returns the JSON Schema type names of the values described by the
+// Shape. Numbers are reported as "integer" if none of them have a fraction
+// or exponent.
+func (sh *Shape) Types() []string {
+	var types []string
+	var k = &sh.Kinds
+
+	if k.Null > 0 {
+		types = append(types, "null")
+	}
+	if k.Bool > 0 {
+		types = append(types, "boolean")
+	}
+	if k.Object > 0 {
+		types = append(types, "object")
+	}
+	if k.Array > 0 {
+		types = append(types, "array")
+	}
+	if k.Number > 0 {
+		if k.Integer == k.Number {
+			types = append(types, "integer")
+		} else {
+			types = append(types, "number")
+		}
+	}
+	if k.String > 0 {
+		types = append(types, "string")
+	}
+
+	return types
+}
+
+// Schema renders the Shape as a JSON Schema document.
+func (sh *Shape) Schema() []byte {
+	return sh.appendSchema(nil)
+}
+
+func (sh *Shape) appendSchema(dst []byte) []byte {
+	dst = append(dst, '{')
+
+	types := sh.Types()
+	if len(types) == 1 {
+		dst = append(dst, `"type":`...)
+		dst = appendQuote(dst, []byte(types[0]))
+	} else if len(types) > 1 {
+		dst = append(dst, `"type":[`...)
+		for i, typ := range types {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendQuote(dst, []byte(typ))
+		}
+		dst = append(dst, ']')
+	}
+
+	if len(sh.Properties) > 0 {
+		keys := make([]string, 0, len(sh.Properties))
+		for key := range sh.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		dst = append(dst, `,"properties":{`...)
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendQuote(dst, []byte(key))
+			dst = append(dst, ':')
+			dst = sh.Properties[key].appendSchema(dst)
+		}
+		dst = append(dst, '}')
+
+		if req := sh.Required(); len(req) > 0 {
+			dst = append(dst, `,"required":[`...)
+			for i, key := range req {
+				if i > 0 {
+					dst = append(dst, ',')
+				}
+				dst = appendQuote(dst, []byte(key))
+			}
+			dst = append(dst, ']')
+		}
+	}
+
+	if sh.Items != nil {
+		dst = append(dst, `,"items":`...)
+		dst = sh.Items.appendSchema(dst)
+	}
+
+	return append(dst, '}')
+}
@@ -0,0 +1,52 @@
+package jo
+
+import (
+	"strings"
+	"testing"
+)
+
+var shapeTests = []struct {
+	in  string
+	out string
+}{
+	{
+		``,
+		`{}`,
+	},
+	{
+		`"foo"`,
+		`{"type":"string"}`,
+	},
+	{
+		`{"id": 1, "tags": ["a"], "geo": {"lat": 1.5}}
+		 {"id": 2, "tags": [], "geo": null, "note": "x"}`,
+		`{"type":"object",` +
+			`"properties":{` +
+			`"geo":{"type":["null","object"],"properties":{"lat":{"type":"number"}},"required":["lat"]},` +
+			`"id":{"type":"integer"},` +
+			`"note":{"type":"string"},` +
+			`"tags":{"type":"array","items":{"type":"string"}}},` +
+			`"required":["geo","id","tags"]}`,
+	},
+	{
+		`[1, "a", [true]]`,
+		`{"type":"array","items":{"type":["array","integer","string"],"items":{"type":"boolean"}}}`,
+	},
+}
+
+func TestShape(t *testing.T) {
+	for _, test := range shapeTests {
+		var sh Shape
+
+		if err := sh.AddLines(strings.NewReader(test.in)); err != nil {
+			t.Errorf("Shape.AddLines(%#q) returned %q", test.in, err)
+			continue
+		}
+
+		if out := string(sh.Schema()); out != test.out {
+			t.Errorf("Shape.AddLines(%#q):", test.in)
+			t.Errorf("  got  %s", out)
+			t.Errorf("  want %s", test.out)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package jo
+
+import (
+	"bytes"
+	"io"
+)
+
+// Kinds counts values by type.
+type Kinds struct {
+	Object, Array, String, Number, Bool, Null int
+
+	// Numbers without a fraction or exponent. Also included in Number.
+	Integer int
+}
+
+// Total returns the total number of values counted.
+func (k *Kinds) Total() int {
+	return k.Object + k.Array + k.String + k.Number + k.Bool + k.Null
+}
+
+// add counts the value starting with tok.
+func (k *Kinds) add(tok token) {
+	switch tok.kind {
+	case ObjectStart:
+		k.Object++
+	case ArrayStart:
+		k.Array++
+	case StringEnd:
+		k.String++
+	case NumberEnd:
+		k.Number++
+		if bytes.IndexAny(tok.text, ".eE") < 0 {
+			k.Integer++
+		}
+	case BoolEnd:
+		k.Bool++
+	case NullEnd:
+		k.Null++
+	}
+}
+
+// isValue reports whether a token marks the beginning of a value.
+func isValue(kind Event) bool {
+	return kind&(ObjectStart|ArrayStart|StringEnd|NumberEnd|BoolEnd|NullEnd) != 0
+}
+
+// Stats summarizes the contents of one or more JSON documents. The zero
+// value is ready to use.
+type Stats struct {
+	// Number of documents and bytes scanned.
+	Documents int
+	Bytes     int64
+
+	// Deepest level of object and array nesting.
+	MaxDepth int
+
+	// Values counted by type.
+	Kinds Kinds
+
+	// Length of the longest string value (after unescaping), and the
+	// largest number of elements or members in a single array or object.
+	LongestString int
+	LargestArray  int
+	LargestObject int
+
+	// Number of times each object key occurs.
+	Keys map[string]int
+
+	// Values counted by type and location. Locations are expressed as
+	// JSON Pointers in which every array index is replaced by "*".
+	Paths map[string]*Kinds
+}
+
+// Add scans a single document from r and adds it to the statistics.
+func (st *Stats) Add(r io.Reader) error {
+	return st.add(newTokenizer(r))
+}
+
+// AddLines scans a stream of newline-separated documents (JSON Lines)
+// from r and adds them to the statistics.
+func (st *Stats) AddLines(r io.Reader) error {
+	t := newTokenizer(r)
+	t.s.SetLines(true)
+	return st.add(t)
+}
+
+func (st *Stats) add(t *tokenizer) error {
+	if st.Keys == nil {
+		st.Keys = make(map[string]int)
+	}
+	if st.Paths == nil {
+		st.Paths = make(map[string]*Kinds)
+	}
+
+	var members []int
+	var str []byte
+
+	defer func() {
+		st.Bytes += t.off
+	}()
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if isValue(tok.kind) {
+			if n := len(members); n > 0 {
+				members[n-1]++
+			} else {
+				st.Documents++
+			}
+		}
+
+		switch tok.kind {
+		case ObjectStart, ArrayStart:
+			members = append(members, 0)
+			if len(members) > st.MaxDepth {
+				st.MaxDepth = len(members)
+			}
+		case ObjectEnd, ArrayEnd:
+			n := members[len(members)-1]
+			members = members[:len(members)-1]
+
+			if tok.kind == ObjectEnd && n > st.LargestObject {
+				st.LargestObject = n
+			} else if tok.kind == ArrayEnd && n > st.LargestArray {
+				st.LargestArray = n
+			}
+			continue
+		case KeyEnd:
+			str = unquote(str[:0], tok.text)
+			st.Keys[string(str)]++
+			continue
+		case StringEnd:
+			str = unquote(str[:0], tok.text)
+			if len(str) > st.LongestString {
+				st.LongestString = len(str)
+			}
+		}
+
+		path := t.pointer(true)
+		k := st.Paths[path]
+		if k == nil {
+			k = new(Kinds)
+			st.Paths[path] = k
+		}
+
+		k.add(tok)
+		st.Kinds.add(tok)
+	}
+}
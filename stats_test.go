@@ -0,0 +1,50 @@
+package jo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	var st Stats
+
+	err := st.AddLines(strings.NewReader(`{"id": 1, "tags": ["a", "bc"], "user": {"name": "Ann"}}
+{"id": 2.5, "tags": [], "user": null}
+[[[]], "xéz"]
+`))
+	if err != nil {
+		t.Fatalf("Stats.AddLines returned %q", err)
+	}
+
+	want := Stats{
+		Documents:     3,
+		Bytes:         109,
+		MaxDepth:      3,
+		Kinds:         Kinds{Object: 3, Array: 5, String: 4, Number: 2, Integer: 1, Null: 1},
+		LongestString: 4,
+		LargestArray:  2,
+		LargestObject: 3,
+		Keys:          map[string]int{"id": 2, "tags": 2, "user": 2, "name": 1},
+		Paths: map[string]*Kinds{
+			"":           {Object: 2, Array: 1},
+			"/id":        {Number: 2, Integer: 1},
+			"/tags":      {Array: 2},
+			"/tags/*":    {String: 2},
+			"/user":      {Object: 1, Null: 1},
+			"/user/name": {String: 1},
+			"/*":         {Array: 1, String: 1},
+			"/*/*":       {Array: 1},
+		},
+	}
+
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("Stats:")
+		t.Errorf("  got  %+v", st)
+		t.Errorf("  want %+v", want)
+	}
+
+	if err := st.Add(strings.NewReader(`{"a": }`)); err == nil {
+		t.Errorf("Stats.Add did not report syntax error")
+	}
+}
@@ -0,0 +1,115 @@
+package jo
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// A stream feeds a document through a Scanner one byte at a time, keeping
+// track of where in the document each event occurs. It is the basis of the
+// streaming transforms, which copy their input to the output with minor
+// changes, using memory proportional only to the nesting depth and the
+// length of keys.
+type stream struct {
+	s *Scanner
+
+	// Enclosing objects and arrays.
+	stack frames
+
+	// Raw text of the key being scanned, and whether one is.
+	key   []byte
+	inKey bool
+}
+
+// run scans a document from r, calling fn for every byte with the byte
+// and the resulting event. At the end of input fn is called once more with
+// c set to -1.
+//
+// When fn is called, end events have already been accounted for, so that
+// the location of a value is up to date when its start event is seen, and
+// an object or array has been left by the time its end event is seen. New
+// objects and arrays are entered after fn returns.
+func (st *stream) run(r io.Reader, fn func(ev Event, c int) error) error {
+	var br = bufio.NewReader(r)
+
+	st.s = NewScanner()
+
+	for {
+		var ev Event
+		var c = -1
+
+		b, err := br.ReadByte()
+		if err == nil {
+			c = int(b)
+			ev = st.s.Scan(b)
+		} else if err == io.EOF {
+			ev = st.s.End()
+		} else {
+			return err
+		}
+
+		if ev == Error {
+			return st.s.LastError()
+		}
+
+		if end := ev & End; end == ObjectEnd || end == ArrayEnd {
+			st.stack.leave()
+		} else if end == KeyEnd {
+			st.inKey = false
+			st.stack.advance(end, st.key)
+		}
+
+		start := ev & Start
+		if start != 0 {
+			st.stack.advance(start, nil)
+		}
+
+		if start == KeyStart {
+			st.key = append(st.key[:0], b)
+			st.inKey = true
+		} else if st.inKey {
+			st.key = append(st.key, b)
+		}
+
+		if err := fn(ev, c); err != nil {
+			return err
+		}
+
+		if c < 0 {
+			return nil
+		}
+
+		if start == ObjectStart || start == ArrayStart {
+			st.stack.enter(start == ArrayStart)
+		}
+	}
+}
+
+// match reports whether the current location matches a path. The wildcard
+// "*" matches any key or index.
+func (st *stream) match(path []string) bool {
+	return len(path) == len(st.stack) && st.prefix(path)
+}
+
+// prefix reports whether the current location begins with path, or, for
+// paths longer than the location, whether the location begins path.
+func (st *stream) prefix(path []string) bool {
+	for i, f := range st.stack {
+		if i == len(path) {
+			break
+		}
+		if tok := path[i]; tok != "*" && !f.is(tok) {
+			return false
+		}
+	}
+	return true
+}
+
+// is reports whether the frame's current key or index equals tok.
+func (f *frame) is(tok string) bool {
+	if f.array {
+		return tok == strconv.Itoa(f.index)
+	}
+	return tok == string(f.key)
+}
@@ -0,0 +1,248 @@
+package jo
+
+import (
+	"io"
+	"strconv"
+)
+
+// A token is a complete lexical element, assembled from Scanner events.
+type token struct {
+	// One of ObjectStart, ObjectEnd, ArrayStart, ArrayEnd, KeyEnd, StringEnd,
+	// NumberEnd, BoolEnd or NullEnd.
+	kind Event
+
+	// Raw text of keys and scalar values, quotes and all. Only valid until
+	// the next call to tokenizer.next.
+	text []byte
+
+	// Offset of the token's first byte.
+	off int64
+
+	// Whether a KeyEnd token repeats an earlier key in the same object.
+	dup bool
+}
+
+// A frame describes an object or array enclosing the current token.
+type frame struct {
+	array bool
+
+	// Index of the current element, or -1 before the first one.
+	index int
+
+	// The current member's unescaped key.
+	key []byte
+}
+
+// frames is a stack of frames, the innermost one last.
+type frames []frame
+
+// enter pushes a frame for an object or array being entered. The key buffer
+// of the frame last at the same depth is reused.
+func (fs *frames) enter(array bool) {
+	n := len(*fs)
+	if n < cap(*fs) {
+		*fs = (*fs)[:n+1]
+	} else {
+		*fs = append(*fs, frame{})
+	}
+
+	f := &(*fs)[n]
+	f.array = array
+	f.index = -1
+	f.key = f.key[:0]
+}
+
+// leave pops the innermost frame.
+func (fs *frames) leave() {
+	*fs = (*fs)[:len(*fs)-1]
+}
+
+// advance updates the innermost frame for a token or event: KeyEnd records
+// the unescaped key, given its raw text, while the start (or end) of a value
+// advances the index of an enclosing array.
+func (fs frames) advance(ev Event, key []byte) {
+	n := len(fs)
+
+	switch {
+	case n == 0, ev&(KeyStart|ObjectEnd|ArrayEnd) != 0:
+	case ev&KeyEnd != 0:
+		fs[n-1].key = unquote(fs[n-1].key[:0], key)
+	case fs[n-1].array:
+		fs[n-1].index++
+	}
+}
+
+// A tokenizer reads JSON text from an io.Reader and assembles the Scanner's
+// events into whole tokens, keeping track of their location in the document.
+type tokenizer struct {
+	s *Scanner
+	r io.Reader
+
+	// Read buffer, and offset of buf[i] in the input.
+	buf  []byte
+	i, n int
+	off  int64
+
+	// Sticky error; io.EOF once all input has been consumed.
+	err error
+	eof bool
+
+	// Literal buffers, alternated between so that the previous token's text
+	// remains intact while the next is being assembled.
+	lit    [2][]byte
+	cur    int
+	inLit  bool
+	litOff int64
+
+	// Tokens waiting to be returned.
+	queue []token
+
+	// Kind of the previously returned token, and the stack of containers
+	// enclosing it. The stack is updated lazily, just before the next token
+	// is returned, so that it accurately describes the current token's
+	// location while it's being inspected.
+	last  Event
+	stack frames
+}
+
+// newTokenizer returns a tokenizer reading from r.
+func newTokenizer(r io.Reader) *tokenizer {
+	return &tokenizer{
+		s:     NewScanner(),
+		r:     r,
+		buf:   make([]byte, 4096),
+		queue: make([]token, 0, 2),
+	}
+}
+
+// next returns the next token, or io.EOF after the last one.
+func (t *tokenizer) next() (token, error) {
+	switch t.last {
+	case ObjectStart, ArrayStart:
+		t.stack.enter(t.last == ArrayStart)
+	case ObjectEnd, ArrayEnd:
+		t.stack.leave()
+	}
+
+	t.last = None
+
+	for len(t.queue) == 0 {
+		if t.err != nil {
+			return token{}, t.err
+		}
+		t.step()
+	}
+
+	tok := t.queue[0]
+	t.queue = t.queue[:copy(t.queue, t.queue[1:])]
+
+	t.stack.advance(tok.kind, tok.text)
+
+	t.last = tok.kind
+	return tok, nil
+}
+
+// step consumes a single byte of input (or the end of input) and queues
+// up any resulting tokens.
+func (t *tokenizer) step() {
+	for t.i == t.n {
+		if t.eof {
+			t.err = io.EOF
+			if ev := t.s.End(); ev == Error {
+				t.err = t.s.LastError()
+			} else {
+				t.emit(ev, 0)
+			}
+			return
+		}
+
+		n, err := t.r.Read(t.buf)
+		if err == io.EOF {
+			t.eof = true
+		} else if err != nil {
+			t.err = err
+			return
+		}
+
+		t.i, t.n = 0, n
+	}
+
+	c := t.buf[t.i]
+	ev := t.s.Scan(c)
+	if ev == Error {
+		t.err = t.s.LastError()
+		return
+	}
+
+	t.emit(ev, c)
+	t.i++
+	t.off++
+}
+
+// emit turns an event into tokens.
+func (t *tokenizer) emit(ev Event, c byte) {
+	if end := ev & End; end != 0 {
+		if end == ObjectEnd || end == ArrayEnd {
+			t.queue = append(t.queue, token{end, nil, t.off - 1, false})
+		} else {
+			t.queue = append(t.queue, token{end, t.lit[t.cur], t.litOff, end == KeyEnd && t.s.Duplicate()})
+			t.inLit = false
+		}
+	}
+
+	if start := ev & Start; start != 0 {
+		if start == ObjectStart || start == ArrayStart {
+			t.queue = append(t.queue, token{start, nil, t.off, false})
+		} else {
+			t.cur ^= 1
+			t.lit[t.cur] = append(t.lit[t.cur][:0], c)
+			t.inLit = true
+			t.litOff = t.off
+		}
+	} else if t.inLit {
+		t.lit[t.cur] = append(t.lit[t.cur], c)
+	}
+}
+
+// pointer returns a JSON Pointer identifying the current token's location.
+// If generic is true, array indices are replaced by "*" so that all
+// elements of an array share the same path.
+func (t *tokenizer) pointer(generic bool) string {
+	var buf []byte
+
+	for i, f := range t.stack {
+		// The innermost frame belongs to the container itself when one of
+		// its end tokens is being inspected.
+		if i == len(t.stack)-1 && t.last&(ObjectEnd|ArrayEnd) != 0 {
+			break
+		}
+
+		buf = append(buf, '/')
+
+		if !f.array {
+			buf = appendPointerToken(buf, f.key)
+		} else if generic {
+			buf = append(buf, '*')
+		} else {
+			buf = strconv.AppendInt(buf, int64(f.index), 10)
+		}
+	}
+
+	return string(buf)
+}
+
+// appendPointerToken appends a JSON Pointer reference token to dst,
+// escaping '~' and '/' characters.
+func appendPointerToken(dst, tok []byte) []byte {
+	for _, c := range tok {
+		switch c {
+		case '~':
+			dst = append(dst, '~', '0')
+		case '/':
+			dst = append(dst, '~', '1')
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,82 @@
+package jo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+var tokenizerTests = []struct {
+	in    string
+	lines bool
+	out   []string
+}{
+	{
+		`{"a": [1, "x", {"b~/c": null}], "d": {}}`,
+		false,
+		[]string{
+			`0 ObjectStart "" ""`,
+			`1 KeyEnd "/a" "\"a\""`,
+			`6 ArrayStart "/a" ""`,
+			`7 NumberEnd "/a/0" "1"`,
+			`10 StringEnd "/a/1" "\"x\""`,
+			`15 ObjectStart "/a/2" ""`,
+			`16 KeyEnd "/a/2/b~0~1c" "\"b~/c\""`,
+			`24 NullEnd "/a/2/b~0~1c" "null"`,
+			`28 ObjectEnd "/a/2" ""`,
+			`29 ArrayEnd "/a" ""`,
+			`32 KeyEnd "/d" "\"d\""`,
+			`37 ObjectStart "/d" ""`,
+			`38 ObjectEnd "/d" ""`,
+			`39 ObjectEnd "" ""`,
+		},
+	},
+	{
+		"1\n[true]\n\n\"a\"\n",
+		true,
+		[]string{
+			`0 NumberEnd "" "1"`,
+			`2 ArrayStart "" ""`,
+			`3 BoolEnd "/0" "true"`,
+			`7 ArrayEnd "" ""`,
+			`10 StringEnd "" "\"a\""`,
+		},
+	},
+	{
+		`[1, 2`,
+		false,
+		[]string{
+			`0 ArrayStart "" ""`,
+			`1 NumberEnd "/0" "1"`,
+			`unexpected end of JSON input, expected digit, '.', 'e', 'E', ',' or ']'`,
+		},
+	},
+}
+
+func TestTokenizer(t *testing.T) {
+	for _, test := range tokenizerTests {
+		var tk = newTokenizer(strings.NewReader(test.in))
+		var out []string
+
+		tk.s.SetLines(test.lines)
+
+		for {
+			tok, err := tk.next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				out = append(out, err.Error())
+				break
+			}
+
+			out = append(out, fmt.Sprintf("%d %s %q %q", tok.off, tok.kind, tk.pointer(false), tok.text))
+		}
+
+		if strings.Join(out, "\n") != strings.Join(test.out, "\n") {
+			t.Errorf("tokenizer(%#q):", test.in)
+			t.Errorf("  got  %q", out)
+			t.Errorf("  want %q", test.out)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package jo
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// Decoded values are represented using the following types:
+//
+//	object         for objects, with members in document order
+//	[]interface{}  for arrays
+//	string         for strings
+//	number         for numbers, in their original form
+//	bool           for booleans
+//	nil            for null
+type (
+	object []member
+	number string
+)
+
+// A member is a key/value pair in an object.
+type member struct {
+	key string
+	val interface{}
+}
+
+// get returns the value of the first member with the specified key.
+func (o object) get(key string) (interface{}, bool) {
+	for _, m := range o {
+		if m.key == key {
+			return m.val, true
+		}
+	}
+	return nil, false
+}
+
+// float returns the number's value as a float64.
+func (n number) float() float64 {
+	f, _ := strconv.ParseFloat(string(n), 64)
+	return f
+}
+
+// A builder assembles tokens into a decoded value.
+type builder struct {
+	stack []partial
+	val   interface{}
+	str   []byte
+
+	// Whether the last of several members with the same key wins.
+	last bool
+}
+
+// A partial is an object or array under construction.
+type partial struct {
+	array bool
+	obj   object
+	arr   []interface{}
+	key   string
+	dup   bool
+}
+
+// add feeds the builder another token, and reports whether it completed
+// the top-level value. The value is then available in b.val.
+func (b *builder) add(tok token) bool {
+	var v interface{}
+
+	switch tok.kind {
+	case ObjectStart, ArrayStart:
+		b.stack = append(b.stack, partial{array: tok.kind == ArrayStart})
+		return false
+	case KeyEnd:
+		b.str = unquote(b.str[:0], tok.text)
+		p := &b.stack[len(b.stack)-1]
+		p.key = string(b.str)
+		p.dup = tok.dup
+		return false
+	case ObjectEnd, ArrayEnd:
+		p := b.stack[len(b.stack)-1]
+		b.stack = b.stack[:len(b.stack)-1]
+		if p.array {
+			if p.arr == nil {
+				p.arr = []interface{}{}
+			}
+			v = p.arr
+		} else {
+			if p.obj == nil {
+				p.obj = object{}
+			}
+			v = p.obj
+		}
+	case StringEnd:
+		b.str = unquote(b.str[:0], tok.text)
+		v = string(b.str)
+	case NumberEnd:
+		v = number(tok.text)
+	case BoolEnd:
+		v = tok.text[0] == 't'
+	case NullEnd:
+		v = nil
+	}
+
+	if n := len(b.stack); n > 0 {
+		if p := &b.stack[n-1]; p.array {
+			p.arr = append(p.arr, v)
+		} else if !p.dup {
+			p.obj = append(p.obj, member{p.key, v})
+		} else if b.last {
+			// The member keeps the position of the first occurrence.
+			for i := range p.obj {
+				if p.obj[i].key == p.key {
+					p.obj[i].val = v
+					break
+				}
+			}
+		}
+		return false
+	}
+
+	b.val = v
+	return true
+}
+
+// decode reads the next complete value from t. Duplicate keys are resolved
+// according to the policy of t's Scanner.
+func decode(t *tokenizer) (interface{}, error) {
+	var b builder
+
+	b.last = t.s.keys != nil && t.s.keys.policy == LastWins
+
+	for {
+		tok, err := t.next()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		if b.add(tok) {
+			return b.val, nil
+		}
+	}
+}
+
+// decodeBytes decodes a complete document.
+func decodeBytes(src []byte) (interface{}, error) {
+	t := newTokenizer(bytes.NewReader(src))
+
+	v, err := decode(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the document doesn't continue past the value.
+	if _, err := t.next(); err != io.EOF {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// equal reports whether two decoded values are equal. Numbers are compared
+// by value, and objects without regard to the order of their members.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case object:
+		b, ok := b.(object)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for _, m := range a {
+			v, ok := b.get(m.key)
+			if !ok || !equal(m.val, v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case number:
+		b, ok := b.(number)
+		return ok && (a == b || a.float() == b.float())
+	default:
+		return a == b
+	}
+}
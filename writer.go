@@ -0,0 +1,102 @@
+package jo
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// A writer emits compact JSON text, inserting commas and colons where
+// they belong. It's used when converting other formats to JSON.
+type writer struct {
+	w *bufio.Writer
+
+	// Whether each enclosing object or array is still empty, and whether
+	// the next value follows a key.
+	empty  []bool
+	member bool
+
+	buf []byte
+}
+
+func newWriter(w io.Writer) *writer {
+	return &writer{w: bufio.NewWriter(w)}
+}
+
+// sep writes the separator preceding a key or value, if any.
+func (w *writer) sep() {
+	if w.member {
+		w.member = false
+	} else if n := len(w.empty); n > 0 {
+		if !w.empty[n-1] {
+			w.w.WriteByte(',')
+		}
+		w.empty[n-1] = false
+	}
+}
+
+// begin opens an object or array.
+func (w *writer) begin(c byte) {
+	w.sep()
+	w.w.WriteByte(c)
+	w.empty = append(w.empty, true)
+}
+
+// end closes an object or array.
+func (w *writer) end(c byte) {
+	w.w.WriteByte(c)
+	w.empty = w.empty[:len(w.empty)-1]
+}
+
+// key writes an object key, which must be followed by a value.
+func (w *writer) key(k []byte) {
+	w.sep()
+	w.buf = appendQuote(w.buf[:0], k)
+	w.w.Write(w.buf)
+	w.w.WriteByte(':')
+	w.member = true
+}
+
+// str writes a string value.
+func (w *writer) str(s []byte) {
+	w.sep()
+	w.buf = appendQuote(w.buf[:0], s)
+	w.w.Write(w.buf)
+}
+
+// raw writes a number or literal.
+func (w *writer) raw(v []byte) {
+	w.sep()
+	w.w.Write(v)
+}
+
+func (w *writer) flush() error {
+	return w.w.Flush()
+}
+
+// value writes a decoded value.
+func (w *writer) value(v interface{}) {
+	switch v := v.(type) {
+	case object:
+		w.begin('{')
+		for _, m := range v {
+			w.key([]byte(m.key))
+			w.value(m.val)
+		}
+		w.end('}')
+	case []interface{}:
+		w.begin('[')
+		for _, el := range v {
+			w.value(el)
+		}
+		w.end(']')
+	case string:
+		w.str([]byte(v))
+	case number:
+		w.raw([]byte(v))
+	case bool:
+		w.raw(strconv.AppendBool(w.buf[:0], v))
+	case nil:
+		w.raw([]byte("null"))
+	}
+}
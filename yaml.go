@@ -0,0 +1,296 @@
+package jo
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"unicode/utf8"
+)
+
+// ToYAML converts a single document read from src to YAML, and writes it to
+// dst. Objects and arrays are written in block style, except for empty ones,
+// with array elements lined up under their parent key:
+//
+//	users:
+//	- name: Ann
+//	  tags:
+//	  - admin
+//	  groups: []
+//
+// Members keep their order, and numbers their exact text. Strings are left
+// unquoted unless YAML 1.2's core schema would otherwise read them as
+// something else, or they contain characters which can't appear in plain
+// scalars; those are double-quoted. Note that this means strings such as
+// "yes" and "off", which YAML 1.1 treats as booleans, are written as is.
+//
+// The document is processed as a stream, without ever being held in memory
+// as a whole. Output written before a syntax error is encountered is not
+// retracted.
+func ToYAML(dst io.Writer, src io.Reader) error {
+	e := &yamlEncoder{t: newTokenizer(src), w: bufio.NewWriter(dst)}
+
+	tok, err := e.t.next()
+	if err == nil {
+		err = e.value(tok, 0, yamlTop)
+	}
+	if err == nil {
+		if _, err = e.t.next(); err == io.EOF {
+			err = nil
+		}
+	}
+
+	if err != nil {
+		e.w.Flush()
+		return err
+	}
+	return e.w.Flush()
+}
+
+// A yamlEncoder writes the tokens of a document as YAML.
+type yamlEncoder struct {
+	t *tokenizer
+	w *bufio.Writer
+
+	// Unquoted strings, and their YAML representation.
+	str, buf []byte
+}
+
+// Positions from which a value can be written.
+const (
+	yamlTop  = iota // at the start of the document
+	yamlKey         // after a key and its colon
+	yamlItem        // after an array element's dash
+)
+
+// value writes the value starting with tok. The line on which it begins
+// is indented by the given number of spaces.
+func (e *yamlEncoder) value(tok token, indent int, pos int) error {
+	if tok.kind != ObjectStart && tok.kind != ArrayStart {
+		if pos != yamlTop {
+			e.w.WriteByte(' ')
+		}
+		if tok.kind == StringEnd {
+			e.str = unquote(e.str[:0], tok.text)
+			e.buf = appendYAMLString(e.buf[:0], e.str)
+			e.w.Write(e.buf)
+		} else {
+			e.w.Write(tok.text)
+		}
+		e.w.WriteByte('\n')
+		return nil
+	}
+
+	first, err := e.t.next()
+	if err != nil {
+		return err
+	}
+
+	if first.kind == ObjectEnd || first.kind == ArrayEnd {
+		if pos != yamlTop {
+			e.w.WriteByte(' ')
+		}
+		if first.kind == ObjectEnd {
+			e.w.WriteString("{}\n")
+		} else {
+			e.w.WriteString("[]\n")
+		}
+		return nil
+	}
+
+	// Members of an object following a key are indented further, while
+	// elements of an array are aligned with the key. Elements of an array
+	// start with the first member or element on the same line as the dash.
+	inline := false
+
+	switch pos {
+	case yamlKey:
+		e.w.WriteByte('\n')
+		if tok.kind == ObjectStart {
+			indent += 2
+		}
+	case yamlItem:
+		e.w.WriteByte(' ')
+		indent += 2
+		inline = true
+	}
+
+	if tok.kind == ObjectStart {
+		return e.members(first, indent, inline)
+	}
+	return e.elements(first, indent, inline)
+}
+
+// members writes the members of an object, starting with the key tok.
+func (e *yamlEncoder) members(tok token, indent int, inline bool) error {
+	var err error
+
+	for tok.kind != ObjectEnd {
+		if !inline {
+			e.indent(indent)
+		}
+		inline = false
+
+		e.str = unquote(e.str[:0], tok.text)
+		e.buf = appendYAMLString(e.buf[:0], e.str)
+		e.w.Write(e.buf)
+		e.w.WriteByte(':')
+
+		if tok, err = e.t.next(); err != nil {
+			return err
+		}
+		if err = e.value(tok, indent, yamlKey); err != nil {
+			return err
+		}
+		if tok, err = e.t.next(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// elements writes the elements of an array, starting with tok.
+func (e *yamlEncoder) elements(tok token, indent int, inline bool) error {
+	var err error
+
+	for tok.kind != ArrayEnd {
+		if !inline {
+			e.indent(indent)
+		}
+		inline = false
+
+		e.w.WriteByte('-')
+
+		if err = e.value(tok, indent, yamlItem); err != nil {
+			return err
+		}
+		if tok, err = e.t.next(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *yamlEncoder) indent(n int) {
+	for i := 0; i < n; i++ {
+		e.w.WriteByte(' ')
+	}
+}
+
+// Plain scalars which YAML 1.2's core schema resolves to null, booleans,
+// integers or floats.
+var yamlReserved = regexp.MustCompile(`^(?:~|null|Null|NULL|true|True|TRUE|false|False|FALSE|` +
+	`[-+]?[0-9]+|0o[0-7]+|0x[0-9a-fA-F]+|` +
+	`[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?|` +
+	`[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN))$`)
+
+// appendYAMLString appends s to dst as a YAML scalar, double-quoted only if
+// it can't be written as a plain one.
+func appendYAMLString(dst, s []byte) []byte {
+	if yamlPlain(s) {
+		return append(dst, s...)
+	}
+
+	dst = append(dst, '"')
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c >= 0x20 && c < 0x7f {
+			if c == '"' || c == '\\' {
+				dst = append(dst, '\\')
+			}
+			dst = append(dst, c)
+			i++
+			continue
+		}
+
+		r, n := utf8.DecodeRune(s[i:])
+		i += n
+
+		switch {
+		case r == utf8.RuneError && n == 1:
+			dst = append(dst, `\ufffd`...)
+		case r == '\n':
+			dst = append(dst, '\\', 'n')
+		case r == '\t':
+			dst = append(dst, '\\', 't')
+		case r == '\r':
+			dst = append(dst, '\\', 'r')
+		case r == 0x85:
+			dst = append(dst, '\\', 'N')
+		case r == 0x2028:
+			dst = append(dst, '\\', 'L')
+		case r == 0x2029:
+			dst = append(dst, '\\', 'P')
+		case r < 0x100 && !yamlPrintable(r):
+			dst = append(dst, '\\', 'x', hexDigits[r>>4], hexDigits[r&0xF])
+		case !yamlPrintable(r) || r == 0xFEFF:
+			dst = append(dst, '\\', 'u',
+				hexDigits[r>>12], hexDigits[r>>8&0xF], hexDigits[r>>4&0xF], hexDigits[r&0xF])
+		default:
+			dst = utf8.AppendRune(dst, r)
+		}
+	}
+
+	return append(dst, '"')
+}
+
+// yamlPlain reports whether s can be written as a plain scalar, in block
+// context, without changing its meaning.
+func yamlPlain(s []byte) bool {
+	if len(s) == 0 || yamlReserved.Match(s) {
+		return false
+	}
+
+	switch s[0] {
+	case '-', '?', ':':
+		if len(s) == 1 || s[1] == ' ' {
+			return false
+		}
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`', ' ':
+		return false
+	}
+
+	if c := s[len(s)-1]; c == ' ' || c == ':' {
+		return false
+	}
+	if bytes.HasPrefix(s, []byte("---")) || bytes.HasPrefix(s, []byte("...")) {
+		return false
+	}
+
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRune(s[i:])
+
+		switch {
+		case r == ':' && s[i+1] == ' ', r == '#' && s[i-1] == ' ':
+			return false
+		case r < 0x20, r == 0x85, r == 0x2028, r == 0x2029, r == 0xFEFF:
+			// Control characters and line breaks.
+			return false
+		case !yamlPrintable(r) || r == utf8.RuneError && n == 1:
+			return false
+		}
+
+		i += n
+	}
+
+	return true
+}
+
+// yamlPrintable reports whether r may appear in a YAML stream unescaped.
+func yamlPrintable(r rune) bool {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r' || r == 0x85:
+		return true
+	case r >= 0x20 && r <= 0x7E:
+		return true
+	case r >= 0xA0 && r <= 0xD7FF, r >= 0xE000 && r <= 0xFFFD:
+		return true
+	case r >= 0x10000 && r <= 0x10FFFF:
+		return true
+	}
+	return false
+}
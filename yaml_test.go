@@ -0,0 +1,124 @@
+package jo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var toYAMLTests = []struct {
+	in  string
+	out string
+	err string
+}{
+	{
+		`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web", "labels": {"app": "web"}},
+		  "spec": {"containers": [{"name": "nginx", "ports": [{"containerPort": 80}], "args": []}],
+		           "replicas": 1.50e1, "paused": false, "node": null, "extra": {}}}`,
+		"apiVersion: v1\n" +
+			"kind: Pod\n" +
+			"metadata:\n" +
+			"  name: web\n" +
+			"  labels:\n" +
+			"    app: web\n" +
+			"spec:\n" +
+			"  containers:\n" +
+			"  - name: nginx\n" +
+			"    ports:\n" +
+			"    - containerPort: 80\n" +
+			"    args: []\n" +
+			"  replicas: 1.50e1\n" +
+			"  paused: false\n" +
+			"  node: null\n" +
+			"  extra: {}\n",
+		``,
+	},
+	{
+		`[[1, [2]], [], {"a": [true]}, "x"]`,
+		"- - 1\n" +
+			"  - - 2\n" +
+			"- []\n" +
+			"- a:\n" +
+			"  - true\n" +
+			"- x\n",
+		``,
+	},
+	{
+		`["true", "Null", "~", "12", "-3.5e2", "0x1F", "0o17", ".inf", ".NaN", "", " a", "a ",
+		  "yes", "-a", "- a", "-", "a: b", "a:b", "a #b", "a#b", "#a", "*a", "a:", "---x", "..",
+		  "tab\there", "line\nbreak", "q\"\\", "\u00e9\u0085\u2028\ufeff\u007f\u0001", "\ud83d\ude00"]`,
+		"- \"true\"\n" +
+			"- \"Null\"\n" +
+			"- \"~\"\n" +
+			"- \"12\"\n" +
+			"- \"-3.5e2\"\n" +
+			"- \"0x1F\"\n" +
+			"- \"0o17\"\n" +
+			"- \".inf\"\n" +
+			"- \".NaN\"\n" +
+			"- \"\"\n" +
+			"- \" a\"\n" +
+			"- \"a \"\n" +
+			"- yes\n" +
+			"- -a\n" +
+			"- \"- a\"\n" +
+			"- \"-\"\n" +
+			"- \"a: b\"\n" +
+			"- a:b\n" +
+			"- \"a #b\"\n" +
+			"- a#b\n" +
+			"- \"#a\"\n" +
+			"- \"*a\"\n" +
+			"- \"a:\"\n" +
+			"- \"---x\"\n" +
+			"- ..\n" +
+			"- \"tab\\there\"\n" +
+			"- \"line\\nbreak\"\n" +
+			"- q\"\\\n" +
+			"- \"\u00e9\\N\\L\\ufeff\\x7f\\x01\"\n" +
+			"- \U0001F600\n",
+		``,
+	},
+	{
+		`{"": 1, "a b": 2, "null": 3, "k: v": {"x": []}}`,
+		"\"\": 1\n" +
+			"a b: 2\n" +
+			"\"null\": 3\n" +
+			"\"k: v\":\n" +
+			"  x: []\n",
+		``,
+	},
+	{
+		`-0.0`,
+		"-0.0\n",
+		``,
+	},
+	{
+		`"12"`,
+		"\"12\"\n",
+		``,
+	},
+	{
+		`{"a": [1, 2`,
+		"a:\n" +
+			"- 1\n",
+		`unexpected end of JSON input, expected digit, '.', 'e', 'E', ',' or ']'`,
+	},
+}
+
+func TestToYAML(t *testing.T) {
+	for _, test := range toYAMLTests {
+		var buf bytes.Buffer
+		var msg string
+
+		if err := ToYAML(&buf, strings.NewReader(test.in)); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.err || buf.String() != test.out {
+			t.Errorf("%#q:", test.in)
+			t.Errorf("  got  %q, %q", buf.String(), msg)
+			t.Errorf("  want %q, %q", test.out, test.err)
+		}
+	}
+}